@@ -13,6 +13,9 @@ type Config struct {
 	GRPC    c.GRPCConf    `mapstructure:"grpc"`
 	Logger  c.LoggerConf  `mapstructure:"logger"`
 	Storage c.StorageConf `mapstructure:"storage"`
+	Auth    c.AuthConf    `mapstructure:"auth"`
+	Agenda  c.AgendaConf  `mapstructure:"agenda"`
+	Trash   c.TrashConf   `mapstructure:"trash"`
 }
 
 func NewConfig(file string) Config {
@@ -30,6 +33,9 @@ func NewConfig(file string) Config {
 		config.GRPC = c.GRPCConfFromEnv()
 		config.Logger = c.LoggerConfFromEnv()
 		config.Storage = c.StorageConfFromEnv()
+		config.Auth = c.AuthConfFromEnv()
+		config.Agenda = c.AgendaConfFromEnv()
+		config.Trash = c.TrashConfFromEnv()
 	}
 	fmt.Fprintf(os.Stderr, "Loaded config %v\n", *config)
 	return *config
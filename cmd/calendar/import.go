@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/VladNF/calendar/internal/app"
+	"github.com/VladNF/calendar/internal/ical"
+)
+
+// importFeeds stores events of iCalendar files, e.g. calendar import -owner alice work.ics home.ics,
+// it returns the exit code which is non zero when some of the entries were not imported
+func importFeeds(calendar *app.App, args []string) int {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	owner := flags.String("owner", "", "Owner of the imported events")
+	_ = flags.Parse(args)
+	if *owner == "" || flags.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: calendar import -owner <owner> <file.ics>...")
+		return 2
+	}
+
+	code := 0
+	for _, name := range flags.Args() {
+		entries, err := decodeFile(name, *owner)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+			code = 1
+			continue
+		}
+		imported := calendar.ImportEvents(context.Background(), entries)
+		for _, entry := range entries {
+			if entry.Err != nil {
+				fmt.Fprintf(os.Stderr, "%s:%d: UID %q: %v\n", name, entry.Line, entry.UID, entry.Err)
+				code = 1
+			}
+		}
+		fmt.Printf("%s: imported %d of %d events\n", name, imported, len(entries))
+	}
+	return code
+}
+
+func decodeFile(name string, owner string) ([]*ical.Entry, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ical.Decode(f, owner)
+}
@@ -8,8 +8,13 @@ import (
 	"syscall"
 	"time"
 
+	// alpine images have no zoneinfo, TZIDs of imported feeds need it.
+	_ "time/tzdata"
+
 	"github.com/VladNF/calendar/internal/app"
+	"github.com/VladNF/calendar/internal/auth"
 	"github.com/VladNF/calendar/internal/common"
+	"github.com/VladNF/calendar/internal/models"
 	servergrpc "github.com/VladNF/calendar/internal/server/grpc"
 	serverhttp "github.com/VladNF/calendar/internal/server/http"
 	"github.com/VladNF/calendar/internal/storage"
@@ -31,14 +36,39 @@ func main() {
 
 	config := NewConfig(configFile)
 	log := common.NewLogger(config.Logger.Level, config.Logger.File)
+	if flag.Arg(0) == "migrate" {
+		os.Exit(migrateSchema(config.Storage, flag.Args()[1:]))
+	}
 
-	eventsRepo, err := storage.NewStorage(config.Storage.Kind)
+	eventsRepo, err := storage.NewStorage(config.Storage)
 	if err != nil {
 		log.Fatalf("storage was not created: %v", err)
 	}
 	calendar := app.New(log, eventsRepo)
-	grpcServer := servergrpc.NewServer(config.GRPC.Host, config.GRPC.Port, log, calendar)
-	httpServer := serverhttp.NewServer(config.HTTP.Host, config.HTTP.Port, log, calendar)
+	audit, err := storage.NewAuditStorage(config.Storage, eventsRepo)
+	if err != nil {
+		log.Fatalf("audit log was not created: %v", err)
+	}
+	calendar.SetAudit(audit)
+	if config.Agenda.WeekStart != "" {
+		weekStart, err := models.ParseWeekday(config.Agenda.WeekStart)
+		if err != nil {
+			log.Fatalf("agenda config is invalid: %v", err)
+		}
+		calendar.SetWeekStart(weekStart)
+	}
+	if flag.Arg(0) == "import" {
+		os.Exit(importFeeds(calendar, flag.Args()[1:]))
+	}
+
+	verifier, err := auth.NewVerifier(config.Auth)
+	if err != nil {
+		log.Fatalf("auth verifier was not created: %v", err)
+	} else if verifier == nil {
+		log.Warn("no auth keys configured, authentication is disabled")
+	}
+	grpcServer := servergrpc.NewServer(config.GRPC.Host, config.GRPC.Port, log, calendar, verifier)
+	httpServer := serverhttp.NewServer(config.HTTP.Host, config.HTTP.Port, log, calendar, verifier)
 
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 	defer cancel()
@@ -46,6 +76,7 @@ func main() {
 	log.Info("calendar is running...")
 	go startServer(grpcServer, log, cancel)
 	go startServer(httpServer, log, cancel)
+	go purgeTrash(ctx, calendar, config.Trash, log)
 
 	<-ctx.Done()
 	stopServer(grpcServer, log)
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/VladNF/calendar/internal/common"
+	"github.com/VladNF/calendar/internal/storage/pgsql"
+)
+
+// migrateSchema changes the schema of the pgsql storage, e.g. calendar migrate up,
+// it returns the exit code which is non zero when the migration failed
+func migrateSchema(conf common.StorageConf, args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: calendar migrate up|down|status")
+		return 2
+	}
+	conf.VerifySchema = false
+	db, err := pgsql.NewPgSQLConnection(conf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "migrate: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	switch args[0] {
+	case "up":
+		applied, err := pgsql.MigrateUp(db)
+		for _, m := range applied {
+			fmt.Printf("applied %03d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate: %v\n", err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Println("schema is up to date")
+		}
+	case "down":
+		reverted, err := pgsql.MigrateDown(db)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate: %v\n", err)
+			return 1
+		}
+		if reverted == nil {
+			fmt.Println("no migrations are applied")
+		} else {
+			fmt.Printf("reverted %03d_%s\n", reverted.Version, reverted.Name)
+		}
+	case "status":
+		states, err := pgsql.MigrationStatus(db)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate: %v\n", err)
+			return 1
+		}
+		for _, s := range states {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = "applied at " + s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%03d_%s\t%s\n", s.Version, s.Name, applied)
+		}
+	default:
+		fmt.Fprintln(os.Stderr, "usage: calendar migrate up|down|status")
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/VladNF/calendar/internal/app"
+	"github.com/VladNF/calendar/internal/common"
+)
+
+const defaultPurgeInterval = time.Hour
+
+// purgeTrash removes events which have been in the trash longer than the retention until ctx is done,
+// the trash is kept as is when the retention is zero
+func purgeTrash(ctx context.Context, calendar *app.App, conf common.TrashConf, log common.Logger) {
+	if conf.Retention <= 0 {
+		return
+	}
+	interval := conf.PurgeInterval
+	if interval <= 0 {
+		interval = defaultPurgeInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if purged, err := calendar.PurgeTrash(ctx, conf.Retention); err != nil {
+			log.Errorf("trash was not purged: %v", err)
+		} else if purged > 0 {
+			log.Infof("purged %d events from the trash", purged)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -33,7 +33,7 @@ func main() {
 	config := NewConfigFromFile(configFile)
 	log := common.NewLogger(config.Logger.Level, config.Logger.File)
 
-	eventsRepo, err := storage.NewStorage(config.Storage.Kind)
+	eventsRepo, err := storage.NewStorage(config.Storage)
 	if err != nil {
 		log.Fatalf("storage was not created: %v", err)
 	}
@@ -98,7 +98,7 @@ func (s *Scheduler) sendAlerts(ctx context.Context) {
 }
 
 func (s *Scheduler) makeAlerts(ctx context.Context) {
-	events, err := s.app.GetDailyAgenda(ctx, time.Now().AddDate(0, 0, -s.config.NoticeDays))
+	events, err := s.app.GetDailyAgenda(ctx, time.Now().AddDate(0, 0, -s.config.NoticeDays), "")
 	if err != nil {
 		s.log.Errorf("make alerts: %v", err)
 	} else {
@@ -106,10 +106,12 @@ func (s *Scheduler) makeAlerts(ctx context.Context) {
 	}
 
 	for _, e := range events {
-		if alert, err := json.Marshal(m.NewAlert(e)); err != nil {
-			s.log.Errorf("make alerts: %v", err)
-		} else {
-			s.alerts <- string(alert)
+		for _, a := range m.NewAlerts(e) {
+			if alert, err := json.Marshal(a); err != nil {
+				s.log.Errorf("make alerts: %v", err)
+			} else {
+				s.alerts <- string(alert)
+			}
 		}
 	}
 }
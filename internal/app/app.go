@@ -2,53 +2,585 @@ package app
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/VladNF/calendar/internal/auth"
 	"github.com/VladNF/calendar/internal/common"
+	"github.com/VladNF/calendar/internal/ical"
 	m "github.com/VladNF/calendar/internal/models"
 )
 
 type App struct {
-	logger common.Logger
-	repo   m.EventsRepo
+	logger    common.Logger
+	repo      m.EventsRepo
+	audit     m.AuditRepo
+	weekStart time.Weekday
+	// audited is the events storage when it keeps the audit log in its own database
+	audited m.AuditedRepo
 }
 
+// New makes an app with weeks starting on Sunday unless SetWeekStart changes it
 func New(logger common.Logger, repo m.EventsRepo) *App {
-	return &App{logger: logger, repo: repo}
+	return &App{logger: logger, repo: repo, weekStart: time.Sunday}
+}
+
+// SetWeekStart sets the first day of the week used when a weekly agenda request does not choose one
+func (app *App) SetWeekStart(weekStart time.Weekday) {
+	app.weekStart = weekStart
+}
+
+// SetAudit makes the app record every change of events into the audit log, changes are not recorded without it.
+// The events storage keeping the log in its own database appends the records in the transactions of the changes,
+// other logs get them after the changes and a record they fail to append is only logged.
+func (app *App) SetAudit(audit m.AuditRepo) {
+	app.audit, app.audited = audit, nil
+	if audited, ok := audit.(m.AuditedRepo); ok && interface{}(audit) == interface{}(app.repo) {
+		app.audited = audited
+	}
+}
+
+// WeekStart returns the default first day of the week
+func (app *App) WeekStart() time.Weekday {
+	return app.weekStart
 }
 
 func (app *App) CreateEvent(
-	ctx context.Context, id, title string, start, end time.Time, owner string,
+	ctx context.Context, id, title string, start, end time.Time, owner string, allowOverlap bool,
 ) (*m.Event, error) {
 	var event *m.Event
 	var err error
 	if event, err = m.NewEvent(id, title, start, end, owner); err != nil {
 		return nil, err
 	}
-	err = app.repo.Put(event)
+	err = app.AddEvent(ctx, event, allowOverlap)
 	return event, err
 }
 
-func (app *App) UpdateEvent(ctx context.Context, event *m.Event) error {
-	return app.repo.Put(event)
+// AddEvent stores a new event rejecting it with ErrAlreadyExists when an event has its ID
+// and with ErrSlotBusy when it overlaps other events of the owner unless the overlap is allowed.
+// The authenticated caller, if there is one, becomes the owner of the event.
+func (app *App) AddEvent(ctx context.Context, event *m.Event, allowOverlap bool) error {
+	if err := event.Validate(); err != nil {
+		return err
+	}
+	if err := app.authorize(ctx, event, nil); err != nil {
+		return err
+	}
+	if !allowOverlap {
+		if err := app.checkSlot(ctx, event); err != nil {
+			return err
+		}
+	}
+	repo, r := app.auditing(ctx, m.AuditCreate, event.ID, nil)
+	if err := repo.Create(ctx, event); err != nil {
+		return err
+	}
+	app.record(ctx, r, event)
+	return nil
+}
+
+// UpdateEvent replaces the stored event rejecting it with ErrNotFound when there is none
+// and with ErrSlotBusy when it overlaps other events of the owner unless the overlap is allowed,
+// occurrences of a recurring event are checked within BusyCheckHorizon of its start and all-day events
+// are never checked as they do not make the time busy.
+// The authenticated caller, if there is one, may update the event only when it can edit events of its owner.
+// Attendees of the event are kept, they are changed by the attendee operations only.
+// A non-zero version of the event must be the stored one, otherwise the event fails with ErrConflict,
+// and a zero version replaces whatever version is stored.
+func (app *App) UpdateEvent(ctx context.Context, event *m.Event, allowOverlap bool) error {
+	if err := event.Validate(); err != nil {
+		return err
+	}
+	stored, err := app.repo.Get(ctx, event.ID)
+	if err != nil {
+		return err
+	}
+	event.Attendees = stored.Attendees
+	if event.Version == 0 {
+		event.Version = stored.Version
+	}
+	if err = app.authorize(ctx, event, stored); err != nil {
+		return err
+	}
+	if !allowOverlap {
+		if err := app.checkSlot(ctx, event); err != nil {
+			return err
+		}
+	}
+	repo, r := app.auditing(ctx, m.AuditUpdate, event.ID, stored)
+	if err = repo.Update(ctx, event); err != nil {
+		return err
+	}
+	app.record(ctx, r, event)
+	return nil
+}
+
+// PatchEvent changes fields of the stored event with apply and stores it the way UpdateEvent does,
+// the changed event is validated again. A non-zero version must be the stored one, otherwise the patch
+// fails with ErrConflict, and the event is stored only if nobody changed it since it was read.
+// The ID, the version and the attendees of the event are not changed by apply.
+func (app *App) PatchEvent(
+	ctx context.Context, id string, version int64, apply func(event *m.Event) error, allowOverlap bool,
+) (*m.Event, error) {
+	stored, err := app.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && version != stored.Version {
+		return nil, fmt.Errorf("%w: event %s is at version %d", m.ErrConflict, id, stored.Version)
+	}
+	event := *stored
+	if err = apply(&event); err != nil {
+		return nil, err
+	}
+	event.ID, event.Version, event.Attendees = stored.ID, stored.Version, stored.Attendees
+	if err = event.Validate(); err != nil {
+		return nil, err
+	}
+	if err = app.authorize(ctx, &event, stored); err != nil {
+		return nil, err
+	}
+	if !allowOverlap {
+		if err = app.checkSlot(ctx, &event); err != nil {
+			return nil, err
+		}
+	}
+	repo, r := app.auditing(ctx, m.AuditUpdate, event.ID, stored)
+	if err = repo.Update(ctx, &event); err != nil {
+		return nil, err
+	}
+	app.record(ctx, r, &event)
+	return &event, nil
+}
+
+// authorize sets the owner of the event for the authenticated caller, it keeps the owner
+// of the stored event and fails with ErrForbidden when the caller may not edit it
+func (app *App) authorize(ctx context.Context, event *m.Event, stored *m.Event) error {
+	identity, ok := auth.FromContext(ctx)
+	switch {
+	case !ok:
+	case stored == nil:
+		event.OwnerID = identity.Subject
+	case !identity.CanEdit(stored.OwnerID):
+		return fmt.Errorf("%w: %s may not edit events of %s", m.ErrForbidden, identity.Subject, stored.OwnerID)
+	default:
+		event.OwnerID = stored.OwnerID
+	}
+	return nil
+}
+
+// BusyCheckHorizon bounds the occurrences of a recurring event which are checked for overlaps,
+// occurrences starting later than it after the start of the event may overlap other events
+const BusyCheckHorizon = 366 * 24 * time.Hour
+
+// checkSlot fails with ErrSlotBusy when the event or one of its occurrences within BusyCheckHorizon
+// overlaps other events of the owner
+func (app *App) checkSlot(ctx context.Context, event *m.Event) error {
+	if event.AllDay || !event.StartsAt.Before(event.EndsAt) {
+		return nil
+	}
+	for _, o := range event.Occurrences(event.StartsAt, event.StartsAt.Add(BusyCheckHorizon)) {
+		busy, err := app.repo.IsOwnerBusy(ctx, event.OwnerID, o.StartsAt, o.EndsAt, event.ID)
+		switch {
+		case err != nil:
+			return err
+		case busy:
+			return fmt.Errorf("%w: %s has other events from %v to %v",
+				m.ErrSlotBusy, event.OwnerID, o.StartsAt, o.EndsAt)
+		}
+	}
+	return nil
 }
 
 func (app *App) GetEvent(ctx context.Context, id string) (*m.Event, error) {
-	return app.repo.Get(id)
+	return app.repo.Get(ctx, id)
 }
 
+// DeleteEvent moves the event into the trash, the authenticated caller must be able to edit events of its owner
 func (app *App) DeleteEvent(ctx context.Context, event *m.Event) error {
-	return app.repo.Delete(event)
+	if identity, ok := auth.FromContext(ctx); ok && !identity.CanEdit(event.OwnerID) {
+		return fmt.Errorf("%w: %s may not delete events of %s", m.ErrForbidden, identity.Subject, event.OwnerID)
+	}
+	repo, r := app.auditing(ctx, m.AuditDelete, event.ID, event)
+	if err := repo.Delete(ctx, event); err != nil {
+		return err
+	}
+	if r == nil {
+		return nil
+	}
+	deleted, err := app.repo.GetDeleted(ctx, event.ID)
+	if err != nil {
+		app.logger.Errorf("delete of event %s was not audited: %v", event.ID, err)
+		return nil
+	}
+	app.record(ctx, r, deleted)
+	return nil
 }
 
-func (app *App) GetDailyAgenda(ctx context.Context, start time.Time) ([]*m.Event, error) {
-	return app.repo.GetDayList(start)
+// ListTrash returns the deleted events of the owner, the last deleted go first. The owner defaults
+// to the authenticated caller who must be able to edit events of the owner, without authentication
+// an empty owner lists the whole trash.
+func (app *App) ListTrash(ctx context.Context, owner string) ([]*m.Event, error) {
+	if identity, ok := auth.FromContext(ctx); ok {
+		if owner == "" {
+			owner = identity.Subject
+		} else if !identity.CanEdit(owner) {
+			return nil, fmt.Errorf("%w: %s may not list the trash of %s", m.ErrForbidden, identity.Subject, owner)
+		}
+	}
+	return app.repo.ListDeleted(ctx, owner)
 }
 
-func (app *App) GetWeeklyAgenda(ctx context.Context, start time.Time) ([]*m.Event, error) {
-	return app.repo.GetWeekList(start)
+// RestoreEvent brings the event back from the trash, a non-zero version must be the version of the deleted
+// event, the authenticated caller must be able to edit events of its owner
+func (app *App) RestoreEvent(ctx context.Context, id string, version int64) (*m.Event, error) {
+	stored, err := app.trashedEvent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	event := *stored
+	event.Version = version
+	repo, r := app.auditing(ctx, m.AuditRestore, id, stored)
+	if err = repo.Restore(ctx, &event); err != nil {
+		return nil, err
+	}
+	restored, err := app.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	app.record(ctx, r, restored)
+	return restored, nil
 }
 
-func (app *App) GetMonthlyAgenda(ctx context.Context, start time.Time) ([]*m.Event, error) {
-	return app.repo.GetMonthList(start)
+// PurgeEvent removes the event from the trash for good, a non-zero version must be the version
+// of the deleted event, the authenticated caller must be able to edit events of its owner
+func (app *App) PurgeEvent(ctx context.Context, id string, version int64) error {
+	stored, err := app.trashedEvent(ctx, id)
+	if err != nil {
+		return err
+	}
+	event := *stored
+	event.Version = version
+	repo, r := app.auditing(ctx, m.AuditPurge, id, stored)
+	if err = repo.Purge(ctx, &event); err != nil {
+		return err
+	}
+	app.record(ctx, r, nil)
+	return nil
+}
+
+// trashedEvent returns the deleted event for the caller who may edit it
+func (app *App) trashedEvent(ctx context.Context, id string) (*m.Event, error) {
+	stored, err := app.repo.GetDeleted(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if identity, ok := auth.FromContext(ctx); ok && !identity.CanEdit(stored.OwnerID) {
+		return nil, fmt.Errorf("%w: %s may not change the trash of %s", m.ErrForbidden, identity.Subject, stored.OwnerID)
+	}
+	return stored, nil
+}
+
+// PurgeTrash removes the events which have been in the trash longer than the retention, the removals
+// are recorded in the audit log one by one the way PurgeEvent records them
+func (app *App) PurgeTrash(ctx context.Context, retention time.Duration) (int, error) {
+	before := time.Now().Add(-retention)
+	if app.audit == nil {
+		return app.repo.PurgeDeleted(ctx, before)
+	}
+	deleted, err := app.repo.ListDeleted(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	for _, stored := range deleted {
+		if !stored.DeletedAt.Before(before) {
+			continue
+		}
+		repo, r := app.auditing(ctx, m.AuditPurge, stored.ID, stored)
+		err = repo.Purge(ctx, stored)
+		switch {
+		case errors.Is(err, m.ErrNotFound) || errors.Is(err, m.ErrConflict):
+			// the event was purged or restored meanwhile
+			continue
+		case err != nil:
+			return purged, err
+		}
+		app.record(ctx, r, nil)
+		purged++
+	}
+	return purged, nil
+}
+
+// GetEventHistory returns the audit records of the event oldest first, the history outlives purged events.
+// It fails with ErrNotFound when the event has no records, the authenticated caller must be able to edit
+// events of the owner the event had at its last change.
+func (app *App) GetEventHistory(ctx context.Context, id string) ([]*m.AuditRecord, error) {
+	if app.audit == nil {
+		return nil, fmt.Errorf("%w: audit log is disabled", m.ErrNotFound)
+	}
+	records, err := app.audit.History(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: event %s has no history", m.ErrNotFound, id)
+	}
+	last := records[len(records)-1]
+	latest := last.Before
+	if last.After != nil {
+		latest = last.After
+	}
+	if identity, ok := auth.FromContext(ctx); ok && !identity.CanEdit(latest.OwnerID) {
+		return nil, fmt.Errorf("%w: %s may not read the history of events of %s",
+			m.ErrForbidden, identity.Subject, latest.OwnerID)
+	}
+	return records, nil
+}
+
+// auditing returns the repo to make the change of the event with and the record of the change the app appends
+// after it, the record is nil when the audit log is disabled or the repo appends it in the transaction of the change
+func (app *App) auditing(ctx context.Context, op m.AuditOp, id string, before *m.Event) (m.EventsRepo, *m.AuditRecord) {
+	if app.audit == nil {
+		return app.repo, nil
+	}
+	r := &m.AuditRecord{EventID: id, At: time.Now().UTC(), Op: op, Before: before}
+	if identity, ok := auth.FromContext(ctx); ok {
+		r.Actor = identity.Subject
+	}
+	if app.audited != nil {
+		return app.audited.WithAudit(r), nil
+	}
+	return app.repo, r
+}
+
+// record appends the record of the change to the audit log, after is the event stored by the change and nil
+// for purged events. The change is stored already so a record which was not appended is only logged.
+func (app *App) record(ctx context.Context, r *m.AuditRecord, after *m.Event) {
+	if r == nil {
+		return
+	}
+	r.After = after
+	if err := app.audit.Append(ctx, r); err != nil {
+		app.logger.Errorf("%s of event %s was not audited: %v", r.Op, r.EventID, err)
+	}
+}
+
+// AddAttendee invites the user to the event, the authenticated caller must be able to edit the event
+func (app *App) AddAttendee(ctx context.Context, eventID, userID string) (*m.Event, error) {
+	return app.changeAttendees(ctx, eventID, func(identity *auth.Identity, event *m.Event) error {
+		if identity != nil && !identity.CanEdit(event.OwnerID) {
+			return fmt.Errorf("%w: %s may not invite to events of %s", m.ErrForbidden, identity.Subject, event.OwnerID)
+		}
+		return event.AddAttendee(userID)
+	})
+}
+
+// RemoveAttendee cancels the invitation, the authenticated caller must be able to edit the event
+func (app *App) RemoveAttendee(ctx context.Context, eventID, userID string) (*m.Event, error) {
+	return app.changeAttendees(ctx, eventID, func(identity *auth.Identity, event *m.Event) error {
+		if identity != nil && !identity.CanEdit(event.OwnerID) {
+			return fmt.Errorf("%w: %s may not uninvite from events of %s", m.ErrForbidden, identity.Subject, event.OwnerID)
+		}
+		return event.RemoveAttendee(userID)
+	})
+}
+
+// RespondToEvent sets the RSVP of the attendee, the authenticated caller must be the attendee
+// or have been granted rights of the attendee
+func (app *App) RespondToEvent(
+	ctx context.Context, eventID, userID string, status m.ResponseStatus,
+) (*m.Event, error) {
+	return app.changeAttendees(ctx, eventID, func(identity *auth.Identity, event *m.Event) error {
+		if identity != nil && !identity.CanEdit(userID) {
+			return fmt.Errorf("%w: %s may not respond for %s", m.ErrForbidden, identity.Subject, userID)
+		}
+		return event.Respond(userID, status)
+	})
+}
+
+func (app *App) changeAttendees(
+	ctx context.Context, eventID string, change func(identity *auth.Identity, event *m.Event) error,
+) (*m.Event, error) {
+	stored, err := app.repo.Get(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	event := *stored
+	identity, _ := auth.FromContext(ctx)
+	if err = change(identity, &event); err != nil {
+		return nil, err
+	}
+	repo, r := app.auditing(ctx, m.AuditUpdate, event.ID, stored)
+	if err = repo.Update(ctx, &event); err != nil {
+		return nil, err
+	}
+	app.record(ctx, r, &event)
+	return &event, nil
+}
+
+// ImportEvents stores valid entries of an iCalendar feed updating the events the owner imported before
+// and sets errors of the entries which were rejected, imported events may overlap other events
+func (app *App) ImportEvents(ctx context.Context, entries []*ical.Entry) (imported int) {
+	for _, entry := range entries {
+		if entry.Err == nil {
+			entry.Err = app.importEvent(ctx, entry)
+		}
+		if entry.Err == nil {
+			imported++
+		}
+	}
+	return imported
+}
+
+// importEvent updates the event of the entry when its owner has it and adds it otherwise, an event
+// of another owner having the ID, e.g. the one a feed of this calendar was exported from, is kept
+// and the entry gets an ID of its owner instead
+func (app *App) importEvent(ctx context.Context, entry *ical.Entry) error {
+	event := entry.Event
+	stored, err := app.repo.Get(ctx, event.ID)
+	if err == nil && stored.OwnerID != event.OwnerID {
+		event.ID = ical.OwnedEventID(event.OwnerID, entry.UID)
+		stored, err = app.repo.Get(ctx, event.ID)
+	}
+	switch {
+	case errors.Is(err, m.ErrNotFound):
+		return app.AddEvent(ctx, event, true)
+	case err != nil:
+		return err
+	case stored.OwnerID != event.OwnerID:
+		return fmt.Errorf("%w: event %s belongs to %s", m.ErrAlreadyExists, event.ID, stored.OwnerID)
+	default:
+		return app.UpdateEvent(ctx, event, true)
+	}
+}
+
+// GetFreeBusy returns merged busy intervals of every owner within [from, to), the intervals all of them
+// are free are returned when minFree is positive, they last at least minFree
+func (app *App) GetFreeBusy(
+	ctx context.Context, owners []string, from, to time.Time, minFree time.Duration,
+) (*m.FreeBusy, error) {
+	if len(owners) == 0 {
+		return nil, fmt.Errorf("%w: at least one owner is required", m.ErrValueError)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: start must be before end", m.ErrValueError)
+	}
+	events, err := app.repo.GetRangeListByOwners(ctx, owners, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	byOwner := make(map[string][]*m.Event, len(owners))
+	for _, e := range events {
+		byOwner[e.OwnerID] = append(byOwner[e.OwnerID], e)
+	}
+	result := &m.FreeBusy{Busy: make(map[string][]m.Interval, len(owners))}
+	var allBusy []m.Interval
+	for _, owner := range owners {
+		busy := m.MergeIntervals(m.BusyIntervals(byOwner[owner], from, to))
+		result.Busy[owner] = busy
+		allBusy = append(allBusy, busy...)
+	}
+	if minFree > 0 {
+		result.Free = m.FreeIntervals(m.MergeIntervals(allBusy), from, to, minFree)
+	}
+	return result, nil
+}
+
+// FindSlots returns the earliest slots when all the attendees of the query are free, larger limits
+// are capped
+func (app *App) FindSlots(ctx context.Context, query m.SlotQuery) ([]m.Interval, error) {
+	if query.Limit > m.MaxSlotLimit {
+		query.Limit = m.MaxSlotLimit
+	}
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	freeBusy, err := app.GetFreeBusy(ctx, query.Attendees, query.From, query.To, query.Duration)
+	if err != nil {
+		return nil, err
+	}
+	return m.FindSlots(freeBusy.Free, query), nil
+}
+
+// SearchEvents finds events by the words of their titles and notes, a zero limit takes the default one
+// and larger limits are capped
+func (app *App) SearchEvents(ctx context.Context, query m.SearchQuery) ([]*m.Event, error) {
+	switch {
+	case query.Limit == 0:
+		query.Limit = m.DefaultSearchLimit
+	case query.Limit > m.MaxSearchLimit:
+		query.Limit = m.MaxSearchLimit
+	}
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	return app.repo.Search(ctx, query)
+}
+
+// GetOwnerEvents lists all events of the owner, recurring events are not expanded
+func (app *App) GetOwnerEvents(ctx context.Context, owner string) ([]*m.Event, error) {
+	return app.repo.GetListByOwner(ctx, owner)
+}
+
+// GetDailyAgenda lists events of the day, an empty owner lists events of all owners
+func (app *App) GetDailyAgenda(ctx context.Context, start time.Time, owner string) ([]*m.Event, error) {
+	if owner != "" {
+		return app.repo.GetDayListByOwner(ctx, owner, start)
+	}
+	return app.repo.GetDayList(ctx, start)
+}
+
+// GetWeeklyAgenda lists events of the week starting on weekStart, an empty owner lists events of all owners
+func (app *App) GetWeeklyAgenda(
+	ctx context.Context, start time.Time, weekStart time.Weekday, owner string,
+) ([]*m.Event, error) {
+	if owner != "" {
+		return app.repo.GetWeekListByOwner(ctx, owner, start, weekStart)
+	}
+	return app.repo.GetWeekList(ctx, start, weekStart)
+}
+
+// GetISOWeekAgenda lists events of the ISO 8601 week like 2026-W42 taken in loc
+func (app *App) GetISOWeekAgenda(
+	ctx context.Context, week string, loc *time.Location, owner string,
+) ([]*m.Event, error) {
+	start, err := m.ParseISOWeek(week, loc)
+	if err != nil {
+		return nil, err
+	}
+	return app.GetWeeklyAgenda(ctx, start, time.Monday, owner)
+}
+
+// ListEventRange lists a page of events overlapping [from, to), the page token is the one of the previous page
+// and empty for the first page, a zero page size takes the default one and larger sizes are capped
+func (app *App) ListEventRange(
+	ctx context.Context, owner string, from, to time.Time, pageSize int, pageToken string,
+) (m.Page, error) {
+	if !from.Before(to) {
+		return m.Page{}, fmt.Errorf("%w: start must be before end", m.ErrValueError)
+	}
+	switch {
+	case pageSize < 0:
+		return m.Page{}, fmt.Errorf("%w: page size must not be negative", m.ErrValueError)
+	case pageSize == 0:
+		pageSize = m.DefaultPageSize
+	case pageSize > m.MaxPageSize:
+		pageSize = m.MaxPageSize
+	}
+	after, err := m.ParseCursor(pageToken)
+	if err != nil {
+		return m.Page{}, err
+	}
+	return app.repo.GetRangePage(ctx, owner, from, to, after, pageSize)
+}
+
+func (app *App) GetMonthlyAgenda(ctx context.Context, start time.Time, owner string) ([]*m.Event, error) {
+	if owner != "" {
+		return app.repo.GetMonthListByOwner(ctx, owner, start)
+	}
+	return app.repo.GetMonthList(ctx, start)
 }
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/VladNF/calendar/internal/common"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// Identity - the authenticated caller along with the owners whose events it may edit,
+// the "*" grant allows editing events of any owner
+type Identity struct {
+	Subject string
+	Grants  []string
+}
+
+// CanEdit tells whether the caller may modify or delete events of the owner
+func (i *Identity) CanEdit(owner string) bool {
+	if i.Subject == owner {
+		return true
+	}
+	for _, grant := range i.Grants {
+		if grant == owner || grant == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+type claims struct {
+	jwt.RegisteredClaims
+	Grants []string `json:"grants,omitempty"`
+}
+
+type identityKey struct{}
+
+func NewContext(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// FromContext returns the caller identity, there is none when authentication is disabled
+func FromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(*Identity)
+	return identity, ok
+}
+
+// Verifier validates JWT bearer tokens signed either with an HMAC secret or an RSA private key
+type Verifier struct {
+	keyFunc jwt.Keyfunc
+}
+
+// NewVerifier makes a verifier from the config, it returns nil when no key is configured
+func NewVerifier(conf common.AuthConf) (*Verifier, error) {
+	switch {
+	case conf.HMACSecret != "":
+		secret := []byte(conf.HMACSecret)
+		return &Verifier{keyFunc: func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+			}
+			return secret, nil
+		}}, nil
+	case conf.RSAPublicKey != "":
+		pem, err := os.ReadFile(conf.RSAPublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("auth: rsa public key -> %w", err)
+		}
+		key, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("auth: rsa public key -> %w", err)
+		}
+		return &Verifier{keyFunc: func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+				return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+			}
+			return key, nil
+		}}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// Verify checks the token signature and expiration, tokens without the expiration claim are rejected,
+// the caller is identified by the subject claim and the edit rights come from the grants claim
+func (v *Verifier) Verify(token string) (*Identity, error) {
+	c := &claims{}
+	if _, err := jwt.ParseWithClaims(token, c, v.keyFunc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	switch {
+	case c.ExpiresAt == nil:
+		return nil, fmt.Errorf("%w: token has no expiration", ErrUnauthenticated)
+	case c.Subject == "":
+		return nil, fmt.Errorf("%w: token has no subject", ErrUnauthenticated)
+	}
+	return &Identity{Subject: c.Subject, Grants: c.Grants}, nil
+}
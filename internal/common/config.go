@@ -1,14 +1,70 @@
 package common
 
-import "github.com/spf13/viper"
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/spf13/viper"
+)
 
 type LoggerConf struct {
 	Level string `mapstructure:"level"`
 	File  string `mapstructure:"file"`
 }
 
+// StorageConf - settings of the storage, the connection settings are used by pgsql storage only
 type StorageConf struct {
+	// Kind is in-memory, sqlite or pgsql
 	Kind string `mapstructure:"kind"`
+	// Path is the database file of sqlite storage or the snapshot file of in-memory storage,
+	// in-memory storage keeps events in memory only when it is empty
+	Path string `mapstructure:"path"`
+	// SnapshotInterval is the least time between snapshots
+	SnapshotInterval time.Duration `mapstructure:"snapshot_interval"`
+	// VerifySchema makes pgsql storage fail at startup unless all the migrations are applied
+	VerifySchema bool `mapstructure:"verify_schema"`
+	// QueryTimeout limits every query of pgsql storage, zero means no limit
+	QueryTimeout time.Duration `mapstructure:"query_timeout"`
+	// AuditCapacity is the number of the last audit records in-memory storage keeps, sql storages keep them all
+	AuditCapacity int `mapstructure:"audit_capacity"`
+	// DSN takes precedence over the separate connection settings below
+	DSN      string `mapstructure:"dsn"`
+	Host     string `mapstructure:"host"`
+	Port     string `mapstructure:"port"`
+	User     string `mapstructure:"user"`
+	Password string `mapstructure:"password"`
+	Database string `mapstructure:"database"`
+	// SSLMode is the sslmode of the connection string, e.g. disable
+	SSLMode string `mapstructure:"sslmode"`
+	// MaxOpenConns, MaxIdleConns and ConnMaxLifetime limit the connection pool the way database/sql does
+	MaxOpenConns    int           `mapstructure:"max_open_conns"`
+	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
+	// ConnectRetries is how many more times startup pings the database after the first failure
+	ConnectRetries int `mapstructure:"connect_retries"`
+	// ConnectBackoff is the wait before the first retry, it doubles after each failure
+	ConnectBackoff time.Duration `mapstructure:"connect_backoff"`
+}
+
+// String hides the password so that the config can be logged
+func (c StorageConf) String() string {
+	dsn := c.DSN
+	if u, err := url.Parse(dsn); err == nil && u.User != nil {
+		dsn = u.Redacted()
+	} else if err != nil {
+		dsn = "xxxxx"
+	}
+	password := ""
+	if c.Password != "" {
+		password = "xxxxx"
+	}
+	return fmt.Sprintf(
+		"{%s %s %v %t %v %d %s %s %s %s %s %s %s %d %d %v %d %v}",
+		c.Kind, c.Path, c.SnapshotInterval, c.VerifySchema, c.QueryTimeout, c.AuditCapacity,
+		dsn, c.Host, c.Port, c.User, password, c.Database, c.SSLMode,
+		c.MaxOpenConns, c.MaxIdleConns, c.ConnMaxLifetime, c.ConnectRetries, c.ConnectBackoff,
+	)
 }
 
 type HTTPConf struct {
@@ -21,6 +77,33 @@ type GRPCConf struct {
 	Port string `mapstructure:"port"`
 }
 
+// AuthConf - keys to verify JWT bearer tokens with, authentication is disabled when none is set
+type AuthConf struct {
+	HMACSecret   string `mapstructure:"hmac_secret"`
+	RSAPublicKey string `mapstructure:"rsa_public_key"`
+}
+
+// String hides the secret so that the config can be logged, the public key is a file path
+func (c AuthConf) String() string {
+	secret := ""
+	if c.HMACSecret != "" {
+		secret = "xxxxx"
+	}
+	return fmt.Sprintf("{%s %s}", secret, c.RSAPublicKey)
+}
+
+// AgendaConf - defaults of agenda requests, WeekStart is a two-letter code like MO, Sunday is used when empty
+type AgendaConf struct {
+	WeekStart string `mapstructure:"week_start"`
+}
+
+// TrashConf - deleted events are purged once they have been in the trash for Retention, the trash is checked
+// every PurgeInterval which defaults to an hour, a zero Retention keeps them until they are purged by hand
+type TrashConf struct {
+	Retention     time.Duration `mapstructure:"retention"`
+	PurgeInterval time.Duration `mapstructure:"purge_interval"`
+}
+
 type MQConf struct {
 	URI      string `mapstructure:"uri"`
 	Exchange string `mapstructure:"exchange"`
@@ -41,7 +124,24 @@ func StorageConfFromEnv() StorageConf {
 	viper.SetEnvPrefix("STORAGE")
 	viper.AutomaticEnv()
 	return StorageConf{
-		Kind: viper.GetString("kind"),
+		Kind:             viper.GetString("kind"),
+		Path:             viper.GetString("path"),
+		SnapshotInterval: viper.GetDuration("snapshot_interval"),
+		VerifySchema:     viper.GetBool("verify_schema"),
+		QueryTimeout:     viper.GetDuration("query_timeout"),
+		AuditCapacity:    viper.GetInt("audit_capacity"),
+		DSN:              viper.GetString("dsn"),
+		Host:             viper.GetString("host"),
+		Port:             viper.GetString("port"),
+		User:             viper.GetString("user"),
+		Password:         viper.GetString("password"),
+		Database:         viper.GetString("database"),
+		SSLMode:          viper.GetString("sslmode"),
+		MaxOpenConns:     viper.GetInt("max_open_conns"),
+		MaxIdleConns:     viper.GetInt("max_idle_conns"),
+		ConnMaxLifetime:  viper.GetDuration("conn_max_lifetime"),
+		ConnectRetries:   viper.GetInt("connect_retries"),
+		ConnectBackoff:   viper.GetDuration("connect_backoff"),
 	}
 }
 
@@ -63,6 +163,32 @@ func HTTPConfFromEnv() HTTPConf {
 	}
 }
 
+func AuthConfFromEnv() AuthConf {
+	viper.SetEnvPrefix("AUTH")
+	viper.AutomaticEnv()
+	return AuthConf{
+		HMACSecret:   viper.GetString("hmac_secret"),
+		RSAPublicKey: viper.GetString("rsa_public_key"),
+	}
+}
+
+func AgendaConfFromEnv() AgendaConf {
+	viper.SetEnvPrefix("AGENDA")
+	viper.AutomaticEnv()
+	return AgendaConf{
+		WeekStart: viper.GetString("week_start"),
+	}
+}
+
+func TrashConfFromEnv() TrashConf {
+	viper.SetEnvPrefix("TRASH")
+	viper.AutomaticEnv()
+	return TrashConf{
+		Retention:     viper.GetDuration("retention"),
+		PurgeInterval: viper.GetDuration("purge_interval"),
+	}
+}
+
 func MQConfFromEnv() MQConf {
 	viper.SetEnvPrefix("MQ")
 	viper.AutomaticEnv()
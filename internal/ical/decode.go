@@ -0,0 +1,338 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VladNF/calendar/internal/models"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrMalformed = errors.New("malformed iCalendar")
+
+	// uidSpace is the namespace of the event IDs derived from UIDs made by other calendars
+	uidSpace      = uuid.MustParse("5b0bd8a5-1c0c-4b5e-9a43-9d0f1a1f3c2e")
+	eventIDRegexp = regexp.MustCompile("^[0-9a-f]{32}$")
+	durRegexp     = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+	textUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+)
+
+// Entry - a VEVENT of a feed, Err is set when the entry can not be turned into an event
+type Entry struct {
+	UID   string
+	Line  int
+	Event *models.Event
+	Err   error
+}
+
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+type component struct {
+	line   int
+	props  []property
+	alarms [][]property
+}
+
+func (c *component) get(name string) (property, bool) {
+	for _, p := range c.props {
+		if p.name == name {
+			return p, true
+		}
+	}
+	return property{}, false
+}
+
+// EventID makes the event ID of a UID the owner imports, the UIDs of events exported by this calendar
+// are the IDs themselves and others are hashed along with the owner so that importing the same feed again
+// updates the same events of the owner whereas other owners importing it get events of their own
+func EventID(owner, uid string) string {
+	if eventIDRegexp.MatchString(uid) {
+		return uid
+	}
+	return OwnedEventID(owner, uid)
+}
+
+// OwnedEventID hashes the UID along with the owner, it is also the ID an event exported by this calendar
+// gets when another owner imports it
+func OwnedEventID(owner, uid string) string {
+	return strings.ReplaceAll(uuid.NewSHA1(uidSpace, []byte(owner+"\n"+uid)).String(), "-", "")
+}
+
+// Decode parses VEVENT entries of a VCALENDAR into events of the owner, an entry which is not a valid
+// event is returned with Err set whereas a malformed feed fails as a whole with ErrMalformed
+func Decode(r io.Reader, owner string) ([]*Entry, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*Entry
+	var event *component
+	var alarm []property
+	var calendars, depth int
+	for _, l := range lines {
+		p, err := parseLine(l.text)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d -> %v", ErrMalformed, l.number, err)
+		}
+		switch {
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VCALENDAR"):
+			calendars++
+		case calendars == 0:
+			return nil, fmt.Errorf("%w: line %d -> content outside of VCALENDAR", ErrMalformed, l.number)
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VEVENT") && depth == 0:
+			event = &component{line: l.number}
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VALARM") && event != nil && depth == 0:
+			alarm, depth = []property{}, 1
+		case p.name == "BEGIN":
+			depth++
+		case p.name == "END" && strings.EqualFold(p.value, "VALARM") && alarm != nil && depth == 1:
+			event.alarms = append(event.alarms, alarm)
+			alarm, depth = nil, 0
+		case p.name == "END" && strings.EqualFold(p.value, "VEVENT") && event != nil && depth == 0:
+			entries = append(entries, newEntry(event, owner))
+			event = nil
+		case p.name == "END" && depth > 0:
+			depth--
+		case p.name == "END":
+		case alarm != nil && depth == 1:
+			alarm = append(alarm, p)
+		case event != nil && depth == 0:
+			event.props = append(event.props, p)
+		}
+	}
+	if calendars == 0 {
+		return nil, fmt.Errorf("%w: no VCALENDAR found", ErrMalformed)
+	}
+	return entries, nil
+}
+
+type contentLine struct {
+	number int
+	text   string
+}
+
+// unfold joins folded lines, a line starting with a space or a tab continues the previous one
+func unfold(r io.Reader) ([]contentLine, error) {
+	var lines []contentLine
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		switch {
+		case text == "":
+		case (text[0] == ' ' || text[0] == '\t') && len(lines) > 0:
+			lines[len(lines)-1].text += text[1:]
+		default:
+			lines = append(lines, contentLine{number: n, text: text})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	return lines, nil
+}
+
+// parseLine splits a content line like DTSTART;TZID=Europe/Berlin:20211004T090000
+// into the name, the parameters and the value, colons and semicolons may be quoted in parameters
+func parseLine(text string) (property, error) {
+	var parts []string
+	quoted, from := false, 0
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '"':
+			quoted = !quoted
+		case c == ';' && !quoted:
+			parts = append(parts, text[from:i])
+			from = i + 1
+		case c == ':' && !quoted:
+			parts = append(parts, text[from:i])
+			p := property{name: strings.ToUpper(parts[0]), params: map[string]string{}, value: text[i+1:]}
+			for _, param := range parts[1:] {
+				kv := strings.SplitN(param, "=", 2)
+				if len(kv) != 2 {
+					return property{}, fmt.Errorf("invalid parameter %q", param)
+				}
+				p.params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], `"`)
+			}
+			if p.name == "" {
+				return property{}, fmt.Errorf("no property name")
+			}
+			return p, nil
+		}
+	}
+	return property{}, fmt.Errorf("no property value in %q", text)
+}
+
+func newEntry(c *component, owner string) *Entry {
+	entry := &Entry{Line: c.line}
+	if uid, ok := c.get("UID"); ok {
+		entry.UID = uid.value
+	}
+	entry.Event, entry.Err = newEvent(c, entry.UID, owner)
+	return entry
+}
+
+func newEvent(c *component, uid string, owner string) (*models.Event, error) {
+	if uid == "" {
+		return nil, fmt.Errorf("%w: UID is missing", models.ErrValueError)
+	}
+	if _, ok := c.get("RECURRENCE-ID"); ok {
+		return nil, fmt.Errorf("%w: changed occurrences of recurring events are not supported", models.ErrValueError)
+	}
+	dtStart, ok := c.get("DTSTART")
+	if !ok {
+		return nil, fmt.Errorf("%w: DTSTART is missing", models.ErrValueError)
+	}
+	start, allDay, err := parseTime(dtStart)
+	if err != nil {
+		return nil, err
+	}
+
+	end := start
+	if dtEnd, ok := c.get("DTEND"); ok {
+		if end, _, err = parseTime(dtEnd); err != nil {
+			return nil, err
+		}
+	} else if dur, ok := c.get("DURATION"); ok {
+		d, err := parseDuration(dur.value)
+		if err != nil {
+			return nil, err
+		}
+		end = start.Add(d)
+	}
+
+	makeEvent := models.NewEvent
+	if allDay {
+		makeEvent = models.NewAllDayEvent
+	}
+	event, err := makeEvent(EventID(owner, uid), propText(c, "SUMMARY"), start, end, owner)
+	if err != nil {
+		return nil, err
+	}
+	event.Notes = propText(c, "DESCRIPTION")
+	if tzid, ok := dtStart.params["TZID"]; ok && !allDay {
+		// recurring events keep their local time of day in the zone of the feed
+		if event.Timezone, err = models.LoadTimezone(tzid); err != nil {
+			return nil, err
+		}
+	}
+	if event.AlertBefore, err = alertBefore(c.alarms); err != nil {
+		return nil, err
+	}
+
+	if rule, ok := c.get("RRULE"); ok {
+		if event.Recurrence, err = models.ParseRRule(rule.value); err != nil {
+			return nil, err
+		}
+		for _, p := range c.props {
+			if p.name != "EXDATE" {
+				continue
+			}
+			for _, value := range strings.Split(p.value, ",") {
+				d, _, err := parseTime(property{name: p.name, params: p.params, value: value})
+				if err != nil {
+					return nil, err
+				}
+				if allDay {
+					d, _ = models.DayWindow(models.Floating(d))
+				}
+				event.Recurrence.ExDates = append(event.Recurrence.ExDates, d)
+			}
+		}
+	}
+	return event, nil
+}
+
+func propText(c *component, name string) string {
+	if p, ok := c.get(name); ok {
+		return textUnescaper.Replace(p.value)
+	}
+	return ""
+}
+
+// parseTime parses a DATE or a DATE-TIME value which is either UTC, in the TZID location
+// or floating, floating times are taken as UTC
+func parseTime(p property) (time.Time, bool, error) {
+	loc := time.UTC
+	if tzid, ok := p.params["TZID"]; ok {
+		var err error
+		if loc, err = time.LoadLocation(tzid); err != nil {
+			return time.Time{}, false, fmt.Errorf("%w: %s has unknown TZID %q", models.ErrValueError, p.name, tzid)
+		}
+	}
+	value := strings.TrimSpace(p.value)
+	layout := localLayout
+	switch {
+	case strings.EqualFold(p.params["VALUE"], "DATE") || len(value) == len(dateLayout):
+		t, err := time.ParseInLocation(dateLayout, value, time.UTC)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("%w: %s has invalid date %q", models.ErrValueError, p.name, value)
+		}
+		return t, true, nil
+	case strings.HasSuffix(value, "Z"):
+		layout, loc = layout+"Z", time.UTC
+	}
+	t, err := time.ParseInLocation(layout, value, loc)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%w: %s has invalid date-time %q", models.ErrValueError, p.name, value)
+	}
+	return t, false, nil
+}
+
+// parseDuration parses an RFC 5545 dur-value like -PT15M or P1W
+func parseDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	m := durRegexp.FindStringSubmatch(value)
+	if m == nil || strings.HasSuffix(value, "T") {
+		return 0, fmt.Errorf("%w: invalid duration %q", models.ErrValueError, value)
+	}
+	var d time.Duration
+	var found bool
+	for i, unit := range []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second} {
+		if m[i+2] != "" {
+			n, _ := strconv.Atoi(m[i+2])
+			d += time.Duration(n) * unit
+			found = true
+		}
+	}
+	if !found {
+		return 0, fmt.Errorf("%w: invalid duration %q", models.ErrValueError, value)
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+// alertBefore takes the earliest alarm triggered before the start of the event,
+// triggers relative to the end or at absolute times are ignored
+func alertBefore(alarms [][]property) (time.Duration, error) {
+	var before time.Duration
+	for _, alarm := range alarms {
+		for _, p := range alarm {
+			if p.name != "TRIGGER" || p.params["VALUE"] == "DATE-TIME" || p.params["RELATED"] == "END" {
+				continue
+			}
+			d, err := parseDuration(p.value)
+			if err != nil {
+				return 0, err
+			}
+			if -d > before {
+				before = -d
+			}
+		}
+	}
+	return before, nil
+}
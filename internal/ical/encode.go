@@ -0,0 +1,125 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/VladNF/calendar/internal/models"
+)
+
+const (
+	prodID      = "-//VladNF//Calendar//EN"
+	dateLayout  = "20060102"
+	localLayout = "20060102T150405"
+	maxLineSize = 75
+)
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// Encode writes events as an RFC 5545 VCALENDAR, a recurring event is written along with its rule
+// whereas an occurrence expanded from it is written as a single instance with RECURRENCE-ID
+func Encode(w io.Writer, events []*models.Event) error {
+	bw := bufio.NewWriter(w)
+	stamp := models.FormatICalTime(time.Now())
+	writeLine(bw, "BEGIN:VCALENDAR")
+	writeLine(bw, "VERSION:2.0")
+	writeLine(bw, "PRODID:"+prodID)
+	writeLine(bw, "CALSCALE:GREGORIAN")
+	for _, e := range events {
+		encodeEvent(bw, e, stamp)
+	}
+	writeLine(bw, "END:VCALENDAR")
+	return bw.Flush()
+}
+
+func encodeEvent(w *bufio.Writer, e *models.Event, stamp string) {
+	writeLine(w, "BEGIN:VEVENT")
+	writeLine(w, "UID:"+e.ID)
+	writeLine(w, "DTSTAMP:"+stamp)
+	writeLine(w, "DTSTART"+timeValue(e, e.StartsAt))
+	writeLine(w, "DTEND"+timeValue(e, e.EndsAt))
+	writeLine(w, "SUMMARY:"+textEscaper.Replace(e.Title))
+	if e.Notes != "" {
+		writeLine(w, "DESCRIPTION:"+textEscaper.Replace(e.Notes))
+	}
+	switch {
+	case !e.OccurrenceStart.IsZero():
+		writeLine(w, "RECURRENCE-ID"+timeValue(e, e.OccurrenceStart))
+	case e.IsRecurring():
+		writeLine(w, "RRULE:"+e.Recurrence.String())
+		for _, d := range e.Recurrence.ExDates {
+			writeLine(w, "EXDATE"+timeValue(e, d))
+		}
+	}
+	if e.AlertBefore >= time.Second {
+		writeLine(w, "BEGIN:VALARM")
+		writeLine(w, "ACTION:DISPLAY")
+		writeLine(w, "DESCRIPTION:"+textEscaper.Replace(e.Title))
+		writeLine(w, "TRIGGER:-"+formatDuration(e.AlertBefore))
+		writeLine(w, "END:VALARM")
+	}
+	writeLine(w, "END:VEVENT")
+}
+
+// timeValue renders the parameters and the value of a date-time property of the event, all-day events use dates
+// and events having a timezone use its local time so that clients expand the rule in it across DST changes,
+// the TZID is an IANA name which the feed carries no VTIMEZONE for
+func timeValue(e *models.Event, t time.Time) string {
+	switch {
+	case e.AllDay:
+		return ";VALUE=DATE:" + t.UTC().Format(dateLayout)
+	case e.Timezone != nil:
+		return ";TZID=" + e.Timezone.String() + ":" + t.In(e.Timezone).Format(localLayout)
+	default:
+		return ":" + models.FormatICalTime(t)
+	}
+}
+
+// formatDuration renders a positive duration as an RFC 5545 dur-value, e.g. PT1H30M or P1D
+func formatDuration(d time.Duration) string {
+	var b strings.Builder
+	b.WriteString("P")
+	if days := d / (24 * time.Hour); days > 0 {
+		fmt.Fprintf(&b, "%dD", days)
+		d -= days * 24 * time.Hour
+	}
+	if d == 0 {
+		return b.String()
+	}
+	b.WriteString("T")
+	if h := d / time.Hour; h > 0 {
+		fmt.Fprintf(&b, "%dH", h)
+		d -= h * time.Hour
+	}
+	if m := d / time.Minute; m > 0 {
+		fmt.Fprintf(&b, "%dM", m)
+		d -= m * time.Minute
+	}
+	if s := d / time.Second; s > 0 {
+		fmt.Fprintf(&b, "%dS", s)
+	}
+	return b.String()
+}
+
+// writeLine writes a content line folding it into lines of at most 75 octets
+// without splitting multi-byte characters
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineSize
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		// continuation lines start with a space
+		limit = maxLineSize - 1
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
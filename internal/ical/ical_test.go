@@ -0,0 +1,42 @@
+package ical
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/VladNF/calendar/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimezoneRoundTrip(t *testing.T) {
+	berlin, err := models.LoadTimezone("Europe/Berlin")
+	require.NoError(t, err)
+	// the weekly meeting stays at 9:00 in Berlin when daylight saving time starts on March 28
+	start := time.Date(2021, 3, 22, 9, 0, 0, 0, berlin)
+	event, err := models.NewEvent("", "Weekly sync", start.UTC(), start.Add(time.Hour).UTC(), "exporter")
+	require.NoError(t, err)
+	event.Timezone = berlin
+	event.Recurrence, err = models.ParseRRule("FREQ=WEEKLY;COUNT=3")
+	require.NoError(t, err)
+	event.Recurrence.ExDates = []time.Time{start.AddDate(0, 0, 7).UTC()}
+
+	var feed bytes.Buffer
+	require.NoError(t, Encode(&feed, []*models.Event{event}))
+	require.Contains(t, feed.String(), "DTSTART;TZID=Europe/Berlin:20210322T090000\r\n")
+	require.Contains(t, feed.String(), "DTEND;TZID=Europe/Berlin:20210322T100000\r\n")
+	require.Contains(t, feed.String(), "EXDATE;TZID=Europe/Berlin:20210329T090000\r\n")
+
+	entries, err := Decode(&feed, "importer")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.NoError(t, entries[0].Err)
+	imported := entries[0].Event
+	require.Equal(t, "Europe/Berlin", imported.Timezone.String())
+	require.True(t, event.StartsAt.Equal(imported.StartsAt))
+
+	occurrences := imported.Occurrences(start, start.AddDate(0, 1, 0))
+	require.Len(t, occurrences, 2)
+	require.True(t, occurrences[0].StartsAt.Equal(time.Date(2021, 3, 22, 8, 0, 0, 0, time.UTC)))
+	require.True(t, occurrences[1].StartsAt.Equal(time.Date(2021, 4, 5, 7, 0, 0, 0, time.UTC)))
+}
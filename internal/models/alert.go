@@ -12,11 +12,26 @@ type Alert struct {
 }
 
 func NewAlert(e *Event) *Alert {
+	return newAlert(e, e.OwnerID)
+}
+
+// NewAlerts makes alerts for the owner and every attendee who accepted the event
+func NewAlerts(e *Event) []*Alert {
+	alerts := []*Alert{NewAlert(e)}
+	for _, a := range e.Attendees {
+		if a.Status == Accepted && a.UserID != e.OwnerID {
+			alerts = append(alerts, newAlert(e, a.UserID))
+		}
+	}
+	return alerts
+}
+
+func newAlert(e *Event, addressee string) *Alert {
 	return &Alert{
 		ID:        uniqueID(),
 		EventID:   e.ID,
 		Title:     e.Title,
 		StartAt:   e.StartsAt,
-		Addressee: e.OwnerID,
+		Addressee: addressee,
 	}
 }
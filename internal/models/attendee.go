@@ -0,0 +1,77 @@
+package models
+
+import "fmt"
+
+// ResponseStatus - RSVP of an attendee, the values are RFC 5545 PARTSTAT ones in lower case
+type ResponseStatus string
+
+const (
+	NeedsAction ResponseStatus = "needs-action"
+	Accepted    ResponseStatus = "accepted"
+	Declined    ResponseStatus = "declined"
+	Tentative   ResponseStatus = "tentative"
+)
+
+// Attendee - a user invited to an event by its owner
+type Attendee struct {
+	UserID string
+	Status ResponseStatus
+}
+
+func ParseResponseStatus(s string) (ResponseStatus, error) {
+	switch status := ResponseStatus(s); status {
+	case NeedsAction, Accepted, Declined, Tentative:
+		return status, nil
+	default:
+		return "", fmt.Errorf("%w: invalid response status %q", ErrValueError, s)
+	}
+}
+
+func (e *Event) attendeeIndex(userID string) int {
+	for i, a := range e.Attendees {
+		if a.UserID == userID {
+			return i
+		}
+	}
+	return -1
+}
+
+// AddAttendee invites the user, an invited user keeps its response
+func (e *Event) AddAttendee(userID string) error {
+	if userID == "" {
+		return fmt.Errorf("%w: attendee must have a user id", ErrValueError)
+	}
+	if e.attendeeIndex(userID) < 0 {
+		attendees := make([]Attendee, len(e.Attendees), len(e.Attendees)+1)
+		copy(attendees, e.Attendees)
+		e.Attendees = append(attendees, Attendee{UserID: userID, Status: NeedsAction})
+	}
+	return nil
+}
+
+func (e *Event) RemoveAttendee(userID string) error {
+	i := e.attendeeIndex(userID)
+	if i < 0 {
+		return fmt.Errorf("%w: %s is not an attendee of %s", ErrNotFound, userID, e.ID)
+	}
+	attendees := make([]Attendee, 0, len(e.Attendees)-1)
+	attendees = append(attendees, e.Attendees[:i]...)
+	e.Attendees = append(attendees, e.Attendees[i+1:]...)
+	return nil
+}
+
+// Respond sets the response of an invited user
+func (e *Event) Respond(userID string, status ResponseStatus) error {
+	if _, err := ParseResponseStatus(string(status)); err != nil {
+		return err
+	}
+	i := e.attendeeIndex(userID)
+	if i < 0 {
+		return fmt.Errorf("%w: %s is not an attendee of %s", ErrNotFound, userID, e.ID)
+	}
+	attendees := make([]Attendee, len(e.Attendees))
+	copy(attendees, e.Attendees)
+	attendees[i].Status = status
+	e.Attendees = attendees
+	return nil
+}
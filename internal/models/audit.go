@@ -0,0 +1,41 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// AuditOp - the kind of change an audit record keeps
+type AuditOp string
+
+const (
+	AuditCreate  AuditOp = "create"
+	AuditUpdate  AuditOp = "update"
+	AuditDelete  AuditOp = "delete"
+	AuditRestore AuditOp = "restore"
+	AuditPurge   AuditOp = "purge"
+)
+
+// AuditRecord - a change of an event made by the actor at the time, Before is nil for created events
+// and After is nil for purged ones, the actor is empty when the change was not authenticated
+type AuditRecord struct {
+	EventID string
+	Actor   string
+	At      time.Time
+	Op      AuditOp
+	Before  *Event
+	After   *Event
+}
+
+// AuditRepo - append-only log of the changes of events, History returns the records of the event
+// oldest first and an empty list when there are none
+type AuditRepo interface {
+	Append(ctx context.Context, r *AuditRecord) error
+	History(ctx context.Context, eventID string) ([]*AuditRecord, error)
+}
+
+// AuditedRepo - events storage keeping the audit log in its own database, the storage WithAudit returns
+// appends the record in the transaction of its next change after it sets After to the event the change stored
+type AuditedRepo interface {
+	WithAudit(r *AuditRecord) EventsRepo
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type attendeeDocument struct {
+	UserID string `json:"user_id"`
+	Status string `json:"status"`
+}
+
+// EventDocument - an event as it is kept in JSON by the in-memory snapshots and the audit log
+type EventDocument struct {
+	ID          string             `json:"id"`
+	Title       string             `json:"title"`
+	StartsAt    time.Time          `json:"start_at"`
+	EndsAt      time.Time          `json:"end_at"`
+	Notes       string             `json:"notes,omitempty"`
+	OwnerID     string             `json:"owner"`
+	AlertBefore int64              `json:"alert_before,omitempty"`
+	RRule       string             `json:"rrule,omitempty"`
+	ExDates     []string           `json:"exdates,omitempty"`
+	AllDay      bool               `json:"all_day,omitempty"`
+	Timezone    string             `json:"timezone,omitempty"`
+	Attendees   []attendeeDocument `json:"attendees,omitempty"`
+	Version     int64              `json:"version"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+	DeletedAt   *time.Time         `json:"deleted_at,omitempty"`
+}
+
+func NewEventDocument(e *Event) *EventDocument {
+	d := &EventDocument{
+		ID:          e.ID,
+		Title:       e.Title,
+		StartsAt:    e.StartsAt,
+		EndsAt:      e.EndsAt,
+		Notes:       e.Notes,
+		OwnerID:     e.OwnerID,
+		AlertBefore: e.AlertBefore.Nanoseconds(),
+		AllDay:      e.AllDay,
+		Version:     e.Version,
+		UpdatedAt:   e.UpdatedAt,
+	}
+	if e.Timezone != nil {
+		d.Timezone = e.Timezone.String()
+	}
+	if e.IsDeleted() {
+		deletedAt := e.DeletedAt
+		d.DeletedAt = &deletedAt
+	}
+	if e.IsRecurring() {
+		d.RRule = e.Recurrence.String()
+		for _, exDate := range e.Recurrence.ExDates {
+			d.ExDates = append(d.ExDates, FormatICalTime(exDate))
+		}
+	}
+	for _, a := range e.Attendees {
+		d.Attendees = append(d.Attendees, attendeeDocument{UserID: a.UserID, Status: string(a.Status)})
+	}
+	return d
+}
+
+// Event returns the event kept in the document
+func (d *EventDocument) Event() (*Event, error) {
+	newEvent := NewEvent
+	if d.AllDay {
+		newEvent = NewAllDayEvent
+	}
+	event, err := newEvent(d.ID, d.Title, d.StartsAt.UTC(), d.EndsAt.UTC(), d.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	event.AlertBefore = time.Duration(d.AlertBefore)
+	event.Notes = d.Notes
+	event.Version = d.Version
+	event.UpdatedAt = d.UpdatedAt
+	if d.DeletedAt != nil {
+		event.DeletedAt = *d.DeletedAt
+	}
+	if d.Timezone != "" {
+		if event.Timezone, err = LoadTimezone(d.Timezone); err != nil {
+			return nil, err
+		}
+	}
+	if d.RRule != "" {
+		if event.Recurrence, err = ParseRRule(d.RRule); err != nil {
+			return nil, err
+		}
+		for _, value := range d.ExDates {
+			exDate, err := ParseICalTime(value)
+			if err != nil {
+				return nil, err
+			}
+			event.Recurrence.ExDates = append(event.Recurrence.ExDates, exDate)
+		}
+	}
+	for _, a := range d.Attendees {
+		status := ResponseStatus(a.Status)
+		event.Attendees = append(event.Attendees, Attendee{UserID: a.UserID, Status: status})
+	}
+	return event, nil
+}
+
+// MarshalEvent encodes the event as a JSON document, a nil event is encoded as nil
+func MarshalEvent(e *Event) ([]byte, error) {
+	if e == nil {
+		return nil, nil
+	}
+	return json.Marshal(NewEventDocument(e))
+}
+
+// UnmarshalEvent decodes the event encoded by MarshalEvent
+func UnmarshalEvent(data []byte) (*Event, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var d EventDocument
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, err
+	}
+	return d.Event()
+}
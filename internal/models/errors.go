@@ -3,8 +3,11 @@ package models
 import "errors"
 
 var (
-	ErrNotFound   = errors.New("not found")
-	ErrSlotBusy   = errors.New("slot busy")
-	ErrValueError = errors.New("value error")
-	ErrDataError  = errors.New("data inconsistency error")
+	ErrNotFound      = errors.New("not found")
+	ErrAlreadyExists = errors.New("already exists")
+	ErrSlotBusy      = errors.New("slot busy")
+	ErrForbidden     = errors.New("forbidden")
+	ErrValueError    = errors.New("value error")
+	ErrDataError     = errors.New("data inconsistency error")
+	ErrConflict      = errors.New("version conflict")
 )
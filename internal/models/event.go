@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -17,21 +18,58 @@ type Event struct {
 	Notes       string
 	OwnerID     string
 	AlertBefore time.Duration
+	Recurrence  *Recurrence
+	// AllDay events span whole dates, StartsAt and EndsAt are UTC midnights of the first
+	// and the day after the last date, they appear on the same dates in any timezone
+	AllDay bool
+	// OccurrenceStart is only set on occurrences expanded from a recurring event
+	OccurrenceStart time.Time
+	Attendees       []Attendee
+	// Timezone is the zone the wall clock of a recurring event is kept in, e.g. a 9:00 weekly
+	// meeting stays at 9:00 when daylight saving time changes, occurrences are expanded in UTC without it
+	Timezone *time.Location
+	// Version counts the times the event was stored, a new event has version 1
+	Version   int64
+	UpdatedAt time.Time
+	// DeletedAt is only set on events in the trash
+	DeletedAt time.Time
 }
 
+// EventsRepo - storage of events, day, week and month lists are taken in the location of d.
+// Create stores a new event and fails with ErrAlreadyExists when the ID is taken. Update fails with ErrNotFound
+// when the event is missing and with ErrConflict unless the version of the event is the stored one,
+// Delete fails with ErrConflict unless the version is the stored one or zero which deletes any version.
+// Both Create and Update increment the version of the event and set UpdatedAt.
+// Deleted events are moved into the trash, they are missing from Get and the lists and keep their IDs taken
+// until Purge or PurgeDeleted removes them for good, Restore brings them back.
+// Delete and Restore increment the version like the writes do.
 type EventsRepo interface {
-	Get(id string) (*Event, error)
-	Put(e *Event) error
-	Delete(e *Event) error
-	GetDayList(d time.Time) ([]*Event, error)
-	GetWeekList(d time.Time) ([]*Event, error)
-	GetMonthList(d time.Time) ([]*Event, error)
-	IsBusy(d1, d2 time.Time) (bool, error)
+	Get(ctx context.Context, id string) (*Event, error)
+	Create(ctx context.Context, e *Event) error
+	Update(ctx context.Context, e *Event) error
+	Delete(ctx context.Context, e *Event) error
+	GetDeleted(ctx context.Context, id string) (*Event, error)
+	ListDeleted(ctx context.Context, owner string) ([]*Event, error)
+	Restore(ctx context.Context, e *Event) error
+	Purge(ctx context.Context, e *Event) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int, error)
+	GetDayList(ctx context.Context, d time.Time) ([]*Event, error)
+	GetWeekList(ctx context.Context, d time.Time, weekStart time.Weekday) ([]*Event, error)
+	GetMonthList(ctx context.Context, d time.Time) ([]*Event, error)
+	IsBusy(ctx context.Context, d1, d2 time.Time) (bool, error)
+	GetDayListByOwner(ctx context.Context, owner string, d time.Time) ([]*Event, error)
+	GetWeekListByOwner(ctx context.Context, owner string, d time.Time, weekStart time.Weekday) ([]*Event, error)
+	GetMonthListByOwner(ctx context.Context, owner string, d time.Time) ([]*Event, error)
+	IsOwnerBusy(ctx context.Context, owner string, d1, d2 time.Time, exceptID string) (bool, error)
+	GetListByOwner(ctx context.Context, owner string) ([]*Event, error)
+	GetRangeListByOwners(ctx context.Context, owners []string, lBound, uBound time.Time) ([]*Event, error)
+	GetRangePage(ctx context.Context, owner string, lBound, uBound time.Time, after *Cursor, size int) (Page, error)
+	Search(ctx context.Context, q SearchQuery) ([]*Event, error)
 }
 
 func NewEvent(id string, title string, start time.Time, end time.Time, owner string) (*Event, error) {
-	if !FitsOneDay(start, end) {
-		return nil, fmt.Errorf("%w: start and end must be of the same date", ErrValueError)
+	if end.Before(start) {
+		return nil, fmt.Errorf("%w: end must not be before start", ErrValueError)
 	}
 
 	if len(id) == 0 {
@@ -46,11 +84,124 @@ func NewEvent(id string, title string, start time.Time, end time.Time, owner str
 	}, nil
 }
 
-func FitsOneDay(start time.Time, end time.Time) bool {
-	y1, m1, d1 := start.Date()
-	y2, m2, d2 := end.Date()
+// NewAllDayEvent makes an event lasting from the date of start till the date of end exclusively,
+// the dates are taken as they are in the locations of start and end, an end date which is not
+// after the start date makes a single day event
+func NewAllDayEvent(id string, title string, start time.Time, end time.Time, owner string) (*Event, error) {
+	firstDay, _ := DayWindow(Floating(start))
+	lastDay, _ := DayWindow(Floating(end))
+	if !lastDay.After(firstDay) {
+		lastDay = firstDay.AddDate(0, 0, 1)
+	}
+
+	if len(id) == 0 {
+		id = uniqueID()
+	}
+	return &Event{
+		ID:       id,
+		Title:    title,
+		StartsAt: firstDay,
+		EndsAt:   lastDay,
+		OwnerID:  owner,
+		AllDay:   true,
+	}, nil
+}
+
+// Validate checks an event whose fields were changed one by one the way NewEvent and NewAllDayEvent check
+// new events and brings its times to the form they make, the recurrence rule is checked against the start
+func (e *Event) Validate() error {
+	newEvent := NewEvent
+	if e.AllDay {
+		newEvent = NewAllDayEvent
+	}
+	valid, err := newEvent(e.ID, e.Title, e.StartsAt, e.EndsAt, e.OwnerID)
+	if err != nil {
+		return err
+	}
+	if e.IsRecurring() {
+		if err = e.Recurrence.ValidateStart(valid.StartsAt.In(e.location())); err != nil {
+			return err
+		}
+	}
+	e.StartsAt, e.EndsAt = valid.StartsAt, valid.EndsAt
+	return nil
+}
+
+// LoadTimezone loads the location of an IANA timezone name like Europe/Berlin
+func LoadTimezone(name string) (*time.Location, error) {
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "" || name == "Local" {
+		return nil, fmt.Errorf("%w: unknown timezone %q", ErrValueError, name)
+	}
+	return loc, nil
+}
+
+// InTimezone converts t into the IANA timezone, an empty name keeps t as is
+func InTimezone(t time.Time, name string) (time.Time, error) {
+	if name == "" {
+		return t, nil
+	}
+	loc, err := LoadTimezone(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.In(loc), nil
+}
+
+// location returns the zone occurrences are expanded in, all-day events are kept in UTC as floating dates
+func (e *Event) location() *time.Location {
+	if e.Timezone == nil || e.AllDay {
+		return time.UTC
+	}
+	return e.Timezone
+}
+
+// IsDeleted tells whether the event is in the trash
+func (e *Event) IsDeleted() bool {
+	return !e.DeletedAt.IsZero()
+}
+
+// IsRecurring tells whether the event is a recurring series rather than a single event
+func (e *Event) IsRecurring() bool {
+	return e.Recurrence != nil
+}
+
+// Occurrences expands a recurring event into copies overlapping [lBound, uBound),
+// each copy keeps the series ID and has OccurrenceStart set,
+// a single event is returned as is when it overlaps the window
+func (e *Event) Occurrences(lBound time.Time, uBound time.Time) []*Event {
+	if e.AllDay {
+		lBound, uBound = Floating(lBound), Floating(uBound)
+	}
+	if !e.IsRecurring() {
+		if overlaps(e.StartsAt, e.EndsAt, lBound, uBound) {
+			return []*Event{e}
+		}
+		return nil
+	}
+
+	var occurrences []*Event
+	duration := e.EndsAt.Sub(e.StartsAt)
+	for _, start := range e.Recurrence.Starts(e.StartsAt.In(e.location()), uBound) {
+		if !overlaps(start, start.Add(duration), lBound, uBound) {
+			continue
+		}
+		o := *e
+		o.StartsAt, o.EndsAt, o.OccurrenceStart = start, start.Add(duration), start
+		occurrences = append(occurrences, &o)
+	}
+	return occurrences
+}
+
+// Overlaps tells whether the event or any occurrence of a recurring one overlaps [lBound, uBound)
+func (e *Event) Overlaps(lBound time.Time, uBound time.Time) bool {
+	return len(e.Occurrences(lBound, uBound)) > 0
+}
 
-	return y1 == y2 && m1 == m2 && d1 == d2
+// overlaps tells whether [start, end) overlaps [lBound, uBound), zero length events starting
+// within the window overlap it as well
+func overlaps(start, end, lBound, uBound time.Time) bool {
+	return start.Before(uBound) && (end.After(lBound) || !start.Before(lBound))
 }
 
 func uniqueID() string {
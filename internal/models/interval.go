@@ -0,0 +1,79 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// Interval - a time span [Start, End)
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// FreeBusy - merged busy intervals of every owner and the intervals all of them are free
+type FreeBusy struct {
+	Busy map[string][]Interval
+	Free []Interval
+}
+
+// BusyIntervals returns spans of timed events clipped to [lBound, uBound),
+// all-day events do not make the time busy
+func BusyIntervals(events []*Event, lBound, uBound time.Time) []Interval {
+	var busy []Interval
+	for _, e := range events {
+		if e.AllDay || !e.StartsAt.Before(e.EndsAt) {
+			continue
+		}
+		i := Interval{Start: e.StartsAt, End: e.EndsAt}
+		if i.Start.Before(lBound) {
+			i.Start = lBound
+		}
+		if i.End.After(uBound) {
+			i.End = uBound
+		}
+		if i.Start.Before(i.End) {
+			busy = append(busy, i)
+		}
+	}
+	return busy
+}
+
+// MergeIntervals returns sorted intervals joining the overlapping and adjacent ones
+func MergeIntervals(intervals []Interval) []Interval {
+	sorted := make([]Interval, len(intervals))
+	copy(sorted, intervals)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	var merged []Interval
+	for _, i := range sorted {
+		if n := len(merged); n > 0 && !i.Start.After(merged[n-1].End) {
+			if i.End.After(merged[n-1].End) {
+				merged[n-1].End = i.End
+			}
+			continue
+		}
+		merged = append(merged, i)
+	}
+	return merged
+}
+
+// FreeIntervals returns gaps between the merged busy intervals within [lBound, uBound)
+// which last at least minDuration
+func FreeIntervals(busy []Interval, lBound, uBound time.Time, minDuration time.Duration) []Interval {
+	var free []Interval
+	start := lBound
+	bounds := append(append([]Interval(nil), busy...), Interval{Start: uBound, End: uBound})
+	for _, b := range bounds {
+		if b.Start.After(uBound) {
+			b.Start = uBound
+		}
+		if b.Start.Sub(start) >= minDuration && b.Start.After(start) {
+			free = append(free, Interval{Start: start, End: b.Start})
+		}
+		if b.End.After(start) {
+			start = b.End
+		}
+	}
+	return free
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultPageSize = 100
+	MaxPageSize     = 1000
+)
+
+// Cursor - position of an event in a listing ordered by start time and ID,
+// occurrences of a recurring event share the ID but not the start time
+type Cursor struct {
+	StartsAt time.Time
+	ID       string
+}
+
+// Page - events of a listing and the token of the next page which is empty on the last page
+type Page struct {
+	Events    []*Event
+	NextToken string
+}
+
+// CursorOf returns the position of the event in a listing
+func CursorOf(e *Event) Cursor {
+	return Cursor{StartsAt: e.StartsAt, ID: e.ID}
+}
+
+// Token encodes the cursor as an opaque page token
+func (c Cursor) Token() string {
+	value := strconv.FormatInt(c.StartsAt.UnixNano(), 10) + "/" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(value))
+}
+
+// ParseCursor decodes a page token, an empty token is the start of a listing and gives nil
+func ParseCursor(token string) (*Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	value, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid page token", ErrValueError)
+	}
+	parts := strings.SplitN(string(value), "/", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("%w: invalid page token", ErrValueError)
+	}
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid page token", ErrValueError)
+	}
+	return &Cursor{StartsAt: time.Unix(0, nanos).UTC(), ID: parts[1]}, nil
+}
+
+// Precedes tells whether the event comes after the cursor in a listing
+func (c *Cursor) Precedes(e *Event) bool {
+	if c == nil {
+		return true
+	}
+	if !e.StartsAt.Equal(c.StartsAt) {
+		return e.StartsAt.After(c.StartsAt)
+	}
+	return e.ID > c.ID
+}
+
+// SortListing orders events by start time and ID
+func SortListing(events []*Event) {
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].StartsAt.Equal(events[j].StartsAt) {
+			return events[i].StartsAt.Before(events[j].StartsAt)
+		}
+		return events[i].ID < events[j].ID
+	})
+}
+
+// SortDeleted orders events of the trash by deletion time, the last deleted go first
+func SortDeleted(events []*Event) {
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].DeletedAt.Equal(events[j].DeletedAt) {
+			return events[i].DeletedAt.After(events[j].DeletedAt)
+		}
+		return events[i].ID < events[j].ID
+	})
+}
+
+// Paginate returns up to size events following the cursor, the events may be in any order
+func Paginate(events []*Event, after *Cursor, size int) Page {
+	var following []*Event
+	for _, e := range events {
+		if after.Precedes(e) {
+			following = append(following, e)
+		}
+	}
+	SortListing(following)
+	if len(following) <= size {
+		return Page{Events: following}
+	}
+	return Page{Events: following[:size], NextToken: CursorOf(following[size-1]).Token()}
+}
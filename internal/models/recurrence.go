@@ -0,0 +1,324 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+const (
+	icalDateTimeUTC = "20060102T150405Z"
+	icalDateTime    = "20060102T150405"
+	icalDate        = "20060102"
+)
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// WeekdayNum - a BYDAY entry, e.g. MO or -1FR (the last Friday of a month)
+type WeekdayNum struct {
+	Ordinal int
+	Weekday time.Weekday
+}
+
+func (w WeekdayNum) String() string {
+	code := strings.ToUpper(w.Weekday.String()[:2])
+	if w.Ordinal != 0 {
+		return strconv.Itoa(w.Ordinal) + code
+	}
+	return code
+}
+
+// Recurrence - a subset of RFC 5545 recurrence rule (FREQ, INTERVAL, BYDAY, COUNT, UNTIL)
+// along with the exception dates (EXDATE) of a recurring event
+type Recurrence struct {
+	Freq     Frequency
+	Interval int
+	ByDay    []WeekdayNum
+	Count    int
+	Until    time.Time
+	ExDates  []time.Time
+}
+
+// ParseRRule parses an RRULE value like FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;UNTIL=20211231T000000Z
+func ParseRRule(rule string) (*Recurrence, error) {
+	r := &Recurrence{Interval: 1}
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	for _, part := range strings.Split(rule, ";") {
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("%w: invalid rrule part %q", ErrValueError, part)
+		}
+		name, value := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+		var err error
+		switch name {
+		case "FREQ":
+			r.Freq = Frequency(value)
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+		case "COUNT":
+			r.Count, err = strconv.Atoi(value)
+		case "UNTIL":
+			r.Until, err = ParseICalTime(value)
+		case "BYDAY":
+			r.ByDay, err = parseByDay(value)
+		default:
+			err = fmt.Errorf("unsupported rrule part %s", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: rrule %s -> %v", ErrValueError, name, err)
+		}
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func parseByDay(value string) ([]WeekdayNum, error) {
+	var days []WeekdayNum
+	for _, code := range strings.Split(value, ",") {
+		if len(code) < 2 {
+			return nil, fmt.Errorf("invalid weekday %q", code)
+		}
+		weekday, ok := weekdayCodes[code[len(code)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday %q", code)
+		}
+		day := WeekdayNum{Weekday: weekday}
+		if ordinal := code[:len(code)-2]; ordinal != "" {
+			n, err := strconv.Atoi(ordinal)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, fmt.Errorf("invalid weekday ordinal %q", code)
+			}
+			day.Ordinal = n
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// ParseICalTime parses a DATE or DATE-TIME value in the iCalendar basic format,
+// values without a trailing Z are treated as UTC
+func ParseICalTime(value string) (time.Time, error) {
+	for _, layout := range []string{icalDateTimeUTC, icalDateTime, icalDate} {
+		if t, err := time.ParseInLocation(layout, value, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date-time %q", value)
+}
+
+func FormatICalTime(t time.Time) string {
+	return t.UTC().Format(icalDateTimeUTC)
+}
+
+func (r *Recurrence) Validate() error {
+	switch r.Freq {
+	case Daily, Weekly, Monthly, Yearly:
+	default:
+		return fmt.Errorf("%w: unsupported rrule frequency %q", ErrValueError, r.Freq)
+	}
+	if r.Interval < 1 {
+		return fmt.Errorf("%w: rrule interval must be positive", ErrValueError)
+	}
+	if r.Count < 0 {
+		return fmt.Errorf("%w: rrule count must not be negative", ErrValueError)
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return fmt.Errorf("%w: rrule count and until must not occur together", ErrValueError)
+	}
+	for _, d := range r.ByDay {
+		if d.Ordinal != 0 && r.Freq != Monthly {
+			return fmt.Errorf("%w: rrule weekday ordinals are supported for monthly rules only", ErrValueError)
+		}
+	}
+	if len(r.ByDay) > 0 && r.Freq == Yearly {
+		return fmt.Errorf("%w: rrule byday is not supported for yearly rules", ErrValueError)
+	}
+	return nil
+}
+
+// ValidateStart checks the rule along with the start of the series, a daily rule stepping whole weeks
+// stays on the weekday of the start so the weekday must be one of BYDAY or the rule never occurs
+func (r *Recurrence) ValidateStart(dtStart time.Time) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	if r.Freq == Daily && len(r.ByDay) > 0 && r.Interval%7 == 0 && !r.matchesWeekday(dtStart.Weekday()) {
+		return fmt.Errorf("%w: rrule never occurs as %s is not in byday", ErrValueError, dtStart.Weekday())
+	}
+	return nil
+}
+
+// String renders the rule as an RRULE value, exception dates are not included
+func (r *Recurrence) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, d := range r.ByDay {
+			days = append(days, d.String())
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+FormatICalTime(r.Until))
+	}
+	return strings.Join(parts, ";")
+}
+
+func (r *Recurrence) isExcluded(t time.Time) bool {
+	for _, ex := range r.ExDates {
+		if ex.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Starts returns the start times of the occurrences of a series beginning at dtStart
+// which are before the upper bound, the exception dates are skipped
+func (r *Recurrence) Starts(dtStart time.Time, uBound time.Time) []time.Time {
+	var starts []time.Time
+	if r.ValidateStart(dtStart) != nil {
+		return starts
+	}
+	generated := 0
+	for period := 0; ; period++ {
+		// periods may have no candidates at all, e.g. months lacking the 31st, so the loop ends on periods too
+		if p := r.periodStart(dtStart, period); !p.Before(uBound) || (!r.Until.IsZero() && p.After(r.Until)) {
+			return starts
+		}
+		for _, c := range r.periodStarts(dtStart, period) {
+			if c.Before(dtStart) {
+				continue
+			}
+			if !c.Before(uBound) || (!r.Until.IsZero() && c.After(r.Until)) {
+				return starts
+			}
+			if generated++; r.Count > 0 && generated > r.Count {
+				return starts
+			}
+			if !r.isExcluded(c) {
+				starts = append(starts, c)
+			}
+		}
+	}
+}
+
+// periodStart returns the midnight the n-th period of a series begins at, no candidate of the period is before it
+func (r *Recurrence) periodStart(dtStart time.Time, n int) time.Time {
+	yy, mm, dd := dtStart.Date()
+	step := n * r.Interval
+	switch r.Freq {
+	case Weekly:
+		dd += 7*step - (int(dtStart.Weekday())+6)%7
+	case Monthly:
+		mm, dd = mm+time.Month(step), 1
+	case Yearly:
+		yy, mm, dd = yy+step, time.January, 1
+	default:
+		dd += step
+	}
+	return time.Date(yy, mm, dd, 0, 0, 0, 0, dtStart.Location())
+}
+
+// periodStarts returns ordered candidate starts of the n-th period of a series
+func (r *Recurrence) periodStarts(dtStart time.Time, n int) []time.Time {
+	yy, mm, dd := dtStart.Date()
+	h, m, s := dtStart.Clock()
+	at := func(y int, mon time.Month, d int) time.Time {
+		return time.Date(y, mon, d, h, m, s, dtStart.Nanosecond(), dtStart.Location())
+	}
+	step := n * r.Interval
+
+	switch r.Freq {
+	case Daily:
+		day := at(yy, mm, dd+step)
+		if len(r.ByDay) > 0 && !r.matchesWeekday(day.Weekday()) {
+			return nil
+		}
+		return []time.Time{day}
+	case Weekly:
+		if len(r.ByDay) == 0 {
+			return []time.Time{at(yy, mm, dd+7*step)}
+		}
+		// weeks start on Monday as the RFC 5545 default WKST=MO
+		monday := dd - (int(dtStart.Weekday())+6)%7 + 7*step
+		days := make([]time.Time, 0, len(r.ByDay))
+		for _, wd := range r.ByDay {
+			days = append(days, at(yy, mm, monday+(int(wd.Weekday)+6)%7))
+		}
+		sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+		return days
+	case Monthly:
+		first := time.Date(yy, mm+time.Month(step), 1, 0, 0, 0, 0, time.UTC)
+		if len(r.ByDay) == 0 {
+			if day := at(first.Year(), first.Month(), dd); day.Day() == dd {
+				return []time.Time{day}
+			}
+			return nil
+		}
+		var days []time.Time
+		for d := first; d.Month() == first.Month(); d = d.AddDate(0, 0, 1) {
+			if r.matchesMonthDay(d) {
+				days = append(days, at(d.Year(), d.Month(), d.Day()))
+			}
+		}
+		return days
+	case Yearly:
+		if day := at(yy+step, mm, dd); day.Day() == dd {
+			return []time.Time{day}
+		}
+		return nil
+	default:
+		return nil
+	}
+}
+
+func (r *Recurrence) matchesWeekday(wd time.Weekday) bool {
+	for _, d := range r.ByDay {
+		if d.Weekday == wd {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Recurrence) matchesMonthDay(d time.Time) bool {
+	daysInMonth := time.Date(d.Year(), d.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	nth, nthLast := (d.Day()-1)/7+1, -((daysInMonth-d.Day())/7 + 1)
+	for _, wd := range r.ByDay {
+		if wd.Weekday == d.Weekday() && (wd.Ordinal == 0 || wd.Ordinal == nth || wd.Ordinal == nthLast) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// startsWithin fails the test instead of hanging when the expansion does not end
+func startsWithin(t *testing.T, r *Recurrence, dtStart, uBound time.Time) []time.Time {
+	done := make(chan []time.Time, 1)
+	go func() { done <- r.Starts(dtStart, uBound) }()
+	select {
+	case starts := <-done:
+		return starts
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expansion of %s from %v did not end", r, dtStart)
+		return nil
+	}
+}
+
+func TestRecurrenceNeverOccurs(t *testing.T) {
+	r, err := ParseRRule("FREQ=DAILY;INTERVAL=7;BYDAY=MO")
+	require.NoError(t, err)
+	tuesday := time.Date(2021, 9, 7, 9, 0, 0, 0, time.UTC)
+	monday := tuesday.AddDate(0, 0, -1)
+	uBound := tuesday.AddDate(1, 0, 0)
+
+	require.ErrorIs(t, r.ValidateStart(tuesday), ErrValueError)
+	require.Empty(t, startsWithin(t, r, tuesday, uBound))
+	event, err := NewEvent("", "Never", tuesday, tuesday.Add(time.Hour), "test")
+	require.NoError(t, err)
+	event.Recurrence = r
+	require.ErrorIs(t, event.Validate(), ErrValueError)
+
+	require.NoError(t, r.ValidateStart(monday))
+	starts := startsWithin(t, r, monday, monday.AddDate(0, 0, 15))
+	require.Equal(t, []time.Time{monday, monday.AddDate(0, 0, 7), monday.AddDate(0, 0, 14)}, starts)
+}
+
+func TestRecurrenceEmptyPeriods(t *testing.T) {
+	// only the months having the 31st occur, the expansion ends on the periods past the bounds
+	r, err := ParseRRule("FREQ=MONTHLY;UNTIL=20210601T000000Z")
+	require.NoError(t, err)
+	dtStart := time.Date(2021, 1, 31, 9, 0, 0, 0, time.UTC)
+	starts := startsWithin(t, r, dtStart, dtStart.AddDate(10, 0, 0))
+	require.Equal(t, []time.Time{dtStart, dtStart.AddDate(0, 2, 0), dtStart.AddDate(0, 4, 0)}, starts)
+
+	r, err = ParseRRule("FREQ=YEARLY")
+	require.NoError(t, err)
+	leapDay := time.Date(2020, 2, 29, 9, 0, 0, 0, time.UTC)
+	starts = startsWithin(t, r, leapDay, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.Equal(t, []time.Time{leapDay, leapDay.AddDate(4, 0, 0)}, starts)
+}
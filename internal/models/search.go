@@ -0,0 +1,64 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	DefaultSearchLimit = 50
+	MaxSearchLimit     = 500
+)
+
+// SearchQuery - a full-text search of events by the words of their titles and notes,
+// the results are limited to the owner and to [From, To) when they are set
+type SearchQuery struct {
+	Text  string
+	Owner string
+	From  time.Time
+	To    time.Time
+	Limit int
+}
+
+func (q *SearchQuery) Validate() error {
+	switch {
+	case len(SearchTerms(q.Text)) == 0:
+		return fmt.Errorf("%w: search text has no words", ErrValueError)
+	case q.From.IsZero() != q.To.IsZero():
+		return fmt.Errorf("%w: search range needs both start and end", ErrValueError)
+	case !q.From.IsZero() && !q.From.Before(q.To):
+		return fmt.Errorf("%w: start must be before end", ErrValueError)
+	case q.Limit <= 0:
+		return fmt.Errorf("%w: search limit must be positive", ErrValueError)
+	}
+	return nil
+}
+
+// HasRange tells whether the results are limited to a date range
+func (q *SearchQuery) HasRange() bool {
+	return !q.From.IsZero()
+}
+
+// SearchTerms splits text into lower case words of letters and digits, events match a search
+// when their titles and notes have all the words of the search text
+func SearchTerms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	seen := make(map[string]bool, len(words))
+	terms := words[:0]
+	for _, w := range words {
+		if !seen[w] {
+			seen[w] = true
+			terms = append(terms, w)
+		}
+	}
+	return terms
+}
+
+// Terms returns the search terms of the title and the notes of the event
+func (e *Event) Terms() []string {
+	return SearchTerms(e.Title + " " + e.Notes)
+}
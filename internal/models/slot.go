@@ -0,0 +1,172 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultSlotLimit = 5
+	MaxSlotLimit     = 100
+	// slotAlignment - slots start at round times, e.g. 9:00 or 9:15 but not 9:07
+	slotAlignment = 15 * time.Minute
+	day           = 24 * time.Hour
+)
+
+// SlotQuery - constraints of a search of meeting slots when all the attendees are free
+type SlotQuery struct {
+	Attendees []string
+	Duration  time.Duration
+	From      time.Time
+	To        time.Time
+	// WorkStart and WorkEnd are offsets from midnight in Location, whole days are used when both are zero
+	WorkStart time.Duration
+	WorkEnd   time.Duration
+	// WorkDays are the days slots may be on, any day is used when it is empty
+	WorkDays []time.Weekday
+	Location *time.Location
+	Limit    int
+}
+
+func (q *SlotQuery) Validate() error {
+	switch {
+	case len(q.Attendees) == 0:
+		return fmt.Errorf("%w: at least one attendee is required", ErrValueError)
+	case q.Duration <= 0:
+		return fmt.Errorf("%w: slot duration must be positive", ErrValueError)
+	case !q.From.Before(q.To):
+		return fmt.Errorf("%w: start must be before end", ErrValueError)
+	case q.WorkStart < 0 || q.WorkEnd > day || q.WorkStart > q.WorkEnd:
+		return fmt.Errorf("%w: working hours must be within a day and start before they end", ErrValueError)
+	case q.WorkStart == q.WorkEnd && q.WorkStart != 0:
+		return fmt.Errorf("%w: working hours must not be empty", ErrValueError)
+	case q.Limit <= 0:
+		return fmt.Errorf("%w: slot limit must be positive", ErrValueError)
+	}
+	return nil
+}
+
+// SetWorkingHours parses working hours like 09:00 and 18:00, weekday codes like MO
+// and an IANA timezone name, empty values are not used
+func (q *SlotQuery) SetWorkingHours(start, end string, days []string, timezone string) error {
+	var err error
+	if start != "" || end != "" {
+		if q.WorkStart, err = ParseClock(start); err != nil {
+			return err
+		}
+		if q.WorkEnd, err = ParseClock(end); err != nil {
+			return err
+		}
+	}
+	q.WorkDays = q.WorkDays[:0]
+	for _, code := range days {
+		weekday, err := ParseWeekday(code)
+		if err != nil {
+			return err
+		}
+		q.WorkDays = append(q.WorkDays, weekday)
+	}
+	q.Location = time.UTC
+	if timezone != "" {
+		if q.Location, err = LoadTimezone(timezone); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseClock parses a time of day like 09:30 into the offset from midnight, 24:00 is the end of a day
+func ParseClock(value string) (time.Duration, error) {
+	var h, m int
+	if n, err := fmt.Sscanf(value, "%d:%d", &h, &m); err != nil || n != 2 || len(value) != 5 {
+		return 0, fmt.Errorf("%w: invalid time of day %q", ErrValueError, value)
+	}
+	if h < 0 || m < 0 || m > 59 || h*60+m > 24*60 {
+		return 0, fmt.Errorf("%w: invalid time of day %q", ErrValueError, value)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+// ParseWeekday parses a two-letter weekday code like MO as used in recurrence rules
+func ParseWeekday(code string) (time.Weekday, error) {
+	if weekday, ok := weekdayCodes[strings.ToUpper(code)]; ok {
+		return weekday, nil
+	}
+	return 0, fmt.Errorf("%w: invalid weekday %q", ErrValueError, code)
+}
+
+// FindSlots returns the earliest slots of the query duration within the sorted free intervals,
+// the slots do not overlap each other and only the working hours of the working days are used
+func FindSlots(free []Interval, q SlotQuery) []Interval {
+	var slots []Interval
+	for _, window := range q.workingWindows() {
+		for _, f := range free {
+			start, end := window.Start, window.End
+			if f.Start.After(start) {
+				start = f.Start
+			}
+			if f.End.Before(end) {
+				end = f.End
+			}
+			if aligned := start.Truncate(slotAlignment); aligned.Before(start) {
+				start = aligned.Add(slotAlignment)
+			}
+			for ; !start.Add(q.Duration).After(end); start = start.Add(q.Duration) {
+				slots = append(slots, Interval{Start: start, End: start.Add(q.Duration)})
+				if len(slots) == q.Limit {
+					return slots
+				}
+			}
+		}
+	}
+	return slots
+}
+
+// workingWindows returns working hours of the working days within [From, To)
+func (q *SlotQuery) workingWindows() []Interval {
+	loc := q.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	workStart, workEnd := q.WorkStart, q.WorkEnd
+	if workStart == 0 && workEnd == 0 {
+		workEnd = day
+	}
+
+	var windows []Interval
+	first, _ := DayWindow(q.From.In(loc))
+	for d := first; d.Before(q.To); d = d.AddDate(0, 0, 1) {
+		if !q.isWorkDay(d.Weekday()) {
+			continue
+		}
+		// wall clock offsets keep working hours right on daylight saving time changes
+		yy, mm, dd := d.Date()
+		w := Interval{
+			Start: time.Date(yy, mm, dd, 0, 0, int(workStart/time.Second), 0, loc),
+			End:   time.Date(yy, mm, dd, 0, 0, int(workEnd/time.Second), 0, loc),
+		}
+		if w.Start.Before(q.From) {
+			w.Start = q.From
+		}
+		if w.End.After(q.To) {
+			w.End = q.To
+		}
+		if w.Start.Before(w.End) {
+			windows = append(windows, w)
+		}
+	}
+	return windows
+}
+
+func (q *SlotQuery) isWorkDay(wd time.Weekday) bool {
+	if len(q.WorkDays) == 0 {
+		return true
+	}
+	for _, d := range q.WorkDays {
+		if d == wd {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// DayWindow returns [lBound, uBound) bounds of the day containing d in the location of d
+func DayWindow(d time.Time) (time.Time, time.Time) {
+	yy, mm, dd := d.Date()
+	lBound := time.Date(yy, mm, dd, 0, 0, 0, 0, d.Location())
+	uBound := time.Date(yy, mm, dd+1, 0, 0, 0, 0, d.Location())
+	return lBound, uBound
+}
+
+// WeekWindow returns [lBound, uBound) bounds of the week containing d, weeks start on weekStart
+func WeekWindow(d time.Time, weekStart time.Weekday) (time.Time, time.Time) {
+	yy, mm, dd := d.Date()
+	offset := (int(d.Weekday()) - int(weekStart) + 7) % 7
+	first := time.Date(yy, mm, dd-offset, 0, 0, 0, 0, d.Location())
+	return first, first.AddDate(0, 0, 7)
+}
+
+// ISOWeek returns the start of the ISO 8601 week of the year in loc, i.e. Monday of the week,
+// week 1 is the week with the first Thursday of the year
+func ISOWeek(year, week int, loc *time.Location) (time.Time, error) {
+	// 4 January is always in week 1
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	monday, _ := WeekWindow(jan4, time.Monday)
+	start := monday.AddDate(0, 0, 7*(week-1))
+	if y, w := start.ISOWeek(); week < 1 || y != year || w != week {
+		return time.Time{}, fmt.Errorf("%w: %d has no week %d", ErrValueError, year, week)
+	}
+	return start, nil
+}
+
+// ParseISOWeek parses an ISO 8601 week like 2026-W42 into the start of the week in loc
+func ParseISOWeek(value string, loc *time.Location) (time.Time, error) {
+	var year, week int
+	if n, err := fmt.Sscanf(value, "%4d-W%2d", &year, &week); err != nil || n != 2 || len(value) != 8 {
+		return time.Time{}, fmt.Errorf("%w: invalid ISO week %q", ErrValueError, value)
+	}
+	return ISOWeek(year, week, loc)
+}
+
+// MonthWindow returns [lBound, uBound) bounds of the month containing d
+func MonthWindow(d time.Time) (time.Time, time.Time) {
+	yy, mm, _ := d.Date()
+	lBound := time.Date(yy, mm, 1, 0, 0, 0, 0, d.Location())
+	uBound := time.Date(yy, mm+1, 1, 0, 0, 0, 0, d.Location())
+	return lBound, uBound
+}
+
+// Floating returns UTC time with the same wall clock as t has in its location,
+// all-day events are stored as floating dates and compared with windows converted this way
+func Floating(t time.Time) time.Time {
+	yy, mm, dd := t.Date()
+	h, m, s := t.Clock()
+	return time.Date(yy, mm, dd, h, m, s, t.Nanosecond(), time.UTC)
+}
@@ -47,7 +47,7 @@ func TestE2EServer(t *testing.T) {
 	t.Run("Delete Event", func(t *testing.T) {
 		event := createEvent(t, tc, startTime)
 
-		rDel, err := tc.DeleteEventWithResponse(ctx, event.Id)
+		rDel, err := tc.DeleteEventWithResponse(ctx, event.Id, &gen.DeleteEventParams{})
 		require.NoError(t, err)
 		require.Equal(t, rDel.StatusCode(), http.StatusOK)
 
@@ -68,7 +68,7 @@ func TestE2EServer(t *testing.T) {
 			StartsAt:    event.StartsAt,
 			Title:       event.Title,
 		}
-		r, err := tc.PutEventWithResponse(ctx, event.Id, gen.PutEventJSONRequestBody(expected))
+		r, err := tc.PutEventWithResponse(ctx, event.Id, &gen.PutEventParams{}, gen.PutEventJSONRequestBody(expected))
 		require.NoError(t, err)
 		require.Equal(t, r.StatusCode(), http.StatusOK)
 		eventsIdentical(t, *r.JSON200, expected)
@@ -90,7 +90,7 @@ func TestE2EServer(t *testing.T) {
 			StartsAt:    startTime,
 			EndsAt:      startTime.Add(time.Hour),
 		}
-		r, err := tc.CreateEventWithResponse(ctx, gen.CreateEventJSONRequestBody(expected))
+		r, err := tc.CreateEventWithResponse(ctx, &gen.CreateEventParams{}, gen.CreateEventJSONRequestBody(expected))
 		require.NoError(t, err)
 		require.Equal(t, r.StatusCode(), http.StatusOK)
 		event := *r.JSON200
@@ -117,9 +117,10 @@ func TestE2EServer(t *testing.T) {
 			Title:       event.Title,
 		}
 
+		startFrom := startDate.AddDate(0, 3, 0)
 		params := &gen.ListEventsParams{
 			Agenda:    "daily",
-			StartFrom: startDate.AddDate(0, 3, 0),
+			StartFrom: &startFrom,
 		}
 		r, err := tc.ListEventsWithResponse(ctx, params)
 		require.NoError(t, err)
@@ -133,7 +134,7 @@ func TestE2EServer(t *testing.T) {
 }
 
 func createEvent(t *testing.T, tc *gen.ClientWithResponses, startTime time.Time) gen.Event {
-	r, err := tc.CreateEventWithResponse(context.Background(), gen.CreateEventJSONRequestBody{
+	r, err := tc.CreateEventWithResponse(context.Background(), &gen.CreateEventParams{}, gen.CreateEventJSONRequestBody{
 		AlertBefore: 0,
 		EndsAt:      startTime.Add(time.Hour),
 		Id:          "",
@@ -148,7 +149,7 @@ func createEvent(t *testing.T, tc *gen.ClientWithResponses, startTime time.Time)
 }
 
 func deleteEvent(t *testing.T, tc *gen.ClientWithResponses, id string) {
-	r, err := tc.DeleteEventWithResponse(context.Background(), id)
+	r, err := tc.DeleteEventWithResponse(context.Background(), id, &gen.DeleteEventParams{})
 	require.NoError(t, err)
 	require.Equal(t, r.StatusCode(), http.StatusOK)
 }
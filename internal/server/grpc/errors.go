@@ -0,0 +1,29 @@
+package servergrpc
+
+import (
+	"errors"
+
+	"github.com/VladNF/calendar/internal/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusError converts an app error into a gRPC status error with the matching code
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, models.ErrValueError):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, models.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, models.ErrSlotBusy):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, models.ErrConflict):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, models.ErrAlreadyExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
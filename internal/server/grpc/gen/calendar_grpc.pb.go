@@ -21,9 +21,32 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type CalendarServiceClient interface {
 	GetEvent(ctx context.Context, in *EventId, opts ...grpc.CallOption) (*Event, error)
+	// stores a new event, it fails with ALREADY_EXISTS when an event has the id
+	CreateEvent(ctx context.Context, in *Event, opts ...grpc.CallOption) (*Event, error)
+	// replaces the event having the id, it fails with NOT_FOUND when there is none and with INVALID_ARGUMENT
+	// without id, events are created by CreateEvent only
 	PutEvent(ctx context.Context, in *Event, opts ...grpc.CallOption) (*Event, error)
+	// changes the fields of the update mask only, see UpdateEventRequest
+	UpdateEvent(ctx context.Context, in *UpdateEventRequest, opts ...grpc.CallOption) (*Event, error)
+	// moves the event into the trash
 	DeleteEvent(ctx context.Context, in *EventId, opts ...grpc.CallOption) (*empty.Empty, error)
+	// deleted events of the owner, the last deleted go first
+	ListTrash(ctx context.Context, in *ListTrashRequest, opts ...grpc.CallOption) (*ListTrashResponse, error)
+	// brings the deleted event back, it fails with NOT_FOUND unless the event is in the trash
+	RestoreEvent(ctx context.Context, in *EventId, opts ...grpc.CallOption) (*Event, error)
+	// removes the deleted event for good, it fails with NOT_FOUND unless the event is in the trash
+	PurgeEvent(ctx context.Context, in *EventId, opts ...grpc.CallOption) (*empty.Empty, error)
+	// changes of the event oldest first, it fails with NOT_FOUND when none were recorded, the history outlives
+	// the event when it is purged, in-memory storage keeps only the last changes of all events
+	GetEventHistory(ctx context.Context, in *EventId, opts ...grpc.CallOption) (*GetEventHistoryResponse, error)
 	ListEvents(ctx context.Context, in *ListEventsRequest, opts ...grpc.CallOption) (*ListEventsResponse, error)
+	ListEventRange(ctx context.Context, in *ListEventRangeRequest, opts ...grpc.CallOption) (*ListEventRangeResponse, error)
+	SearchEvents(ctx context.Context, in *SearchEventsRequest, opts ...grpc.CallOption) (*SearchEventsResponse, error)
+	AddAttendee(ctx context.Context, in *AttendeeRequest, opts ...grpc.CallOption) (*Event, error)
+	RemoveAttendee(ctx context.Context, in *AttendeeRequest, opts ...grpc.CallOption) (*Event, error)
+	RespondToEvent(ctx context.Context, in *AttendeeRequest, opts ...grpc.CallOption) (*Event, error)
+	GetFreeBusy(ctx context.Context, in *FreeBusyRequest, opts ...grpc.CallOption) (*FreeBusyResponse, error)
+	FindSlots(ctx context.Context, in *FindSlotsRequest, opts ...grpc.CallOption) (*FindSlotsResponse, error)
 }
 
 type calendarServiceClient struct {
@@ -43,6 +66,15 @@ func (c *calendarServiceClient) GetEvent(ctx context.Context, in *EventId, opts
 	return out, nil
 }
 
+func (c *calendarServiceClient) CreateEvent(ctx context.Context, in *Event, opts ...grpc.CallOption) (*Event, error) {
+	out := new(Event)
+	err := c.cc.Invoke(ctx, "/calendar.CalendarService/CreateEvent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *calendarServiceClient) PutEvent(ctx context.Context, in *Event, opts ...grpc.CallOption) (*Event, error) {
 	out := new(Event)
 	err := c.cc.Invoke(ctx, "/calendar.CalendarService/PutEvent", in, out, opts...)
@@ -52,6 +84,15 @@ func (c *calendarServiceClient) PutEvent(ctx context.Context, in *Event, opts ..
 	return out, nil
 }
 
+func (c *calendarServiceClient) UpdateEvent(ctx context.Context, in *UpdateEventRequest, opts ...grpc.CallOption) (*Event, error) {
+	out := new(Event)
+	err := c.cc.Invoke(ctx, "/calendar.CalendarService/UpdateEvent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *calendarServiceClient) DeleteEvent(ctx context.Context, in *EventId, opts ...grpc.CallOption) (*empty.Empty, error) {
 	out := new(empty.Empty)
 	err := c.cc.Invoke(ctx, "/calendar.CalendarService/DeleteEvent", in, out, opts...)
@@ -61,6 +102,42 @@ func (c *calendarServiceClient) DeleteEvent(ctx context.Context, in *EventId, op
 	return out, nil
 }
 
+func (c *calendarServiceClient) ListTrash(ctx context.Context, in *ListTrashRequest, opts ...grpc.CallOption) (*ListTrashResponse, error) {
+	out := new(ListTrashResponse)
+	err := c.cc.Invoke(ctx, "/calendar.CalendarService/ListTrash", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calendarServiceClient) RestoreEvent(ctx context.Context, in *EventId, opts ...grpc.CallOption) (*Event, error) {
+	out := new(Event)
+	err := c.cc.Invoke(ctx, "/calendar.CalendarService/RestoreEvent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calendarServiceClient) PurgeEvent(ctx context.Context, in *EventId, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/calendar.CalendarService/PurgeEvent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calendarServiceClient) GetEventHistory(ctx context.Context, in *EventId, opts ...grpc.CallOption) (*GetEventHistoryResponse, error) {
+	out := new(GetEventHistoryResponse)
+	err := c.cc.Invoke(ctx, "/calendar.CalendarService/GetEventHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *calendarServiceClient) ListEvents(ctx context.Context, in *ListEventsRequest, opts ...grpc.CallOption) (*ListEventsResponse, error) {
 	out := new(ListEventsResponse)
 	err := c.cc.Invoke(ctx, "/calendar.CalendarService/ListEvents", in, out, opts...)
@@ -70,14 +147,100 @@ func (c *calendarServiceClient) ListEvents(ctx context.Context, in *ListEventsRe
 	return out, nil
 }
 
+func (c *calendarServiceClient) ListEventRange(ctx context.Context, in *ListEventRangeRequest, opts ...grpc.CallOption) (*ListEventRangeResponse, error) {
+	out := new(ListEventRangeResponse)
+	err := c.cc.Invoke(ctx, "/calendar.CalendarService/ListEventRange", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calendarServiceClient) SearchEvents(ctx context.Context, in *SearchEventsRequest, opts ...grpc.CallOption) (*SearchEventsResponse, error) {
+	out := new(SearchEventsResponse)
+	err := c.cc.Invoke(ctx, "/calendar.CalendarService/SearchEvents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calendarServiceClient) AddAttendee(ctx context.Context, in *AttendeeRequest, opts ...grpc.CallOption) (*Event, error) {
+	out := new(Event)
+	err := c.cc.Invoke(ctx, "/calendar.CalendarService/AddAttendee", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calendarServiceClient) RemoveAttendee(ctx context.Context, in *AttendeeRequest, opts ...grpc.CallOption) (*Event, error) {
+	out := new(Event)
+	err := c.cc.Invoke(ctx, "/calendar.CalendarService/RemoveAttendee", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calendarServiceClient) RespondToEvent(ctx context.Context, in *AttendeeRequest, opts ...grpc.CallOption) (*Event, error) {
+	out := new(Event)
+	err := c.cc.Invoke(ctx, "/calendar.CalendarService/RespondToEvent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calendarServiceClient) GetFreeBusy(ctx context.Context, in *FreeBusyRequest, opts ...grpc.CallOption) (*FreeBusyResponse, error) {
+	out := new(FreeBusyResponse)
+	err := c.cc.Invoke(ctx, "/calendar.CalendarService/GetFreeBusy", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calendarServiceClient) FindSlots(ctx context.Context, in *FindSlotsRequest, opts ...grpc.CallOption) (*FindSlotsResponse, error) {
+	out := new(FindSlotsResponse)
+	err := c.cc.Invoke(ctx, "/calendar.CalendarService/FindSlots", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CalendarServiceServer is the server API for CalendarService service.
 // All implementations should embed UnimplementedCalendarServiceServer
 // for forward compatibility
 type CalendarServiceServer interface {
 	GetEvent(context.Context, *EventId) (*Event, error)
+	// stores a new event, it fails with ALREADY_EXISTS when an event has the id
+	CreateEvent(context.Context, *Event) (*Event, error)
+	// replaces the event having the id, it fails with NOT_FOUND when there is none and with INVALID_ARGUMENT
+	// without id, events are created by CreateEvent only
 	PutEvent(context.Context, *Event) (*Event, error)
+	// changes the fields of the update mask only, see UpdateEventRequest
+	UpdateEvent(context.Context, *UpdateEventRequest) (*Event, error)
+	// moves the event into the trash
 	DeleteEvent(context.Context, *EventId) (*empty.Empty, error)
+	// deleted events of the owner, the last deleted go first
+	ListTrash(context.Context, *ListTrashRequest) (*ListTrashResponse, error)
+	// brings the deleted event back, it fails with NOT_FOUND unless the event is in the trash
+	RestoreEvent(context.Context, *EventId) (*Event, error)
+	// removes the deleted event for good, it fails with NOT_FOUND unless the event is in the trash
+	PurgeEvent(context.Context, *EventId) (*empty.Empty, error)
+	// changes of the event oldest first, it fails with NOT_FOUND when none were recorded, the history outlives
+	// the event when it is purged, in-memory storage keeps only the last changes of all events
+	GetEventHistory(context.Context, *EventId) (*GetEventHistoryResponse, error)
 	ListEvents(context.Context, *ListEventsRequest) (*ListEventsResponse, error)
+	ListEventRange(context.Context, *ListEventRangeRequest) (*ListEventRangeResponse, error)
+	SearchEvents(context.Context, *SearchEventsRequest) (*SearchEventsResponse, error)
+	AddAttendee(context.Context, *AttendeeRequest) (*Event, error)
+	RemoveAttendee(context.Context, *AttendeeRequest) (*Event, error)
+	RespondToEvent(context.Context, *AttendeeRequest) (*Event, error)
+	GetFreeBusy(context.Context, *FreeBusyRequest) (*FreeBusyResponse, error)
+	FindSlots(context.Context, *FindSlotsRequest) (*FindSlotsResponse, error)
 }
 
 // UnimplementedCalendarServiceServer should be embedded to have forward compatible implementations.
@@ -87,18 +250,70 @@ func (UnimplementedCalendarServiceServer) GetEvent(context.Context, *EventId) (*
 	return nil, status.Errorf(codes.Unimplemented, "method GetEvent not implemented")
 }
 
+func (UnimplementedCalendarServiceServer) CreateEvent(context.Context, *Event) (*Event, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateEvent not implemented")
+}
+
 func (UnimplementedCalendarServiceServer) PutEvent(context.Context, *Event) (*Event, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method PutEvent not implemented")
 }
 
+func (UnimplementedCalendarServiceServer) UpdateEvent(context.Context, *UpdateEventRequest) (*Event, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateEvent not implemented")
+}
+
 func (UnimplementedCalendarServiceServer) DeleteEvent(context.Context, *EventId) (*empty.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteEvent not implemented")
 }
 
+func (UnimplementedCalendarServiceServer) ListTrash(context.Context, *ListTrashRequest) (*ListTrashResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTrash not implemented")
+}
+
+func (UnimplementedCalendarServiceServer) RestoreEvent(context.Context, *EventId) (*Event, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreEvent not implemented")
+}
+
+func (UnimplementedCalendarServiceServer) PurgeEvent(context.Context, *EventId) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeEvent not implemented")
+}
+
+func (UnimplementedCalendarServiceServer) GetEventHistory(context.Context, *EventId) (*GetEventHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEventHistory not implemented")
+}
+
 func (UnimplementedCalendarServiceServer) ListEvents(context.Context, *ListEventsRequest) (*ListEventsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListEvents not implemented")
 }
 
+func (UnimplementedCalendarServiceServer) ListEventRange(context.Context, *ListEventRangeRequest) (*ListEventRangeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListEventRange not implemented")
+}
+
+func (UnimplementedCalendarServiceServer) SearchEvents(context.Context, *SearchEventsRequest) (*SearchEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchEvents not implemented")
+}
+
+func (UnimplementedCalendarServiceServer) AddAttendee(context.Context, *AttendeeRequest) (*Event, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddAttendee not implemented")
+}
+
+func (UnimplementedCalendarServiceServer) RemoveAttendee(context.Context, *AttendeeRequest) (*Event, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveAttendee not implemented")
+}
+
+func (UnimplementedCalendarServiceServer) RespondToEvent(context.Context, *AttendeeRequest) (*Event, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RespondToEvent not implemented")
+}
+
+func (UnimplementedCalendarServiceServer) GetFreeBusy(context.Context, *FreeBusyRequest) (*FreeBusyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFreeBusy not implemented")
+}
+
+func (UnimplementedCalendarServiceServer) FindSlots(context.Context, *FindSlotsRequest) (*FindSlotsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindSlots not implemented")
+}
+
 // UnsafeCalendarServiceServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to CalendarServiceServer will
 // result in compilation errors.
@@ -128,6 +343,24 @@ func _CalendarService_GetEvent_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CalendarService_CreateEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Event)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarServiceServer).CreateEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/calendar.CalendarService/CreateEvent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarServiceServer).CreateEvent(ctx, req.(*Event))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _CalendarService_PutEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Event)
 	if err := dec(in); err != nil {
@@ -146,6 +379,24 @@ func _CalendarService_PutEvent_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CalendarService_UpdateEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarServiceServer).UpdateEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/calendar.CalendarService/UpdateEvent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarServiceServer).UpdateEvent(ctx, req.(*UpdateEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _CalendarService_DeleteEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(EventId)
 	if err := dec(in); err != nil {
@@ -164,6 +415,78 @@ func _CalendarService_DeleteEvent_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CalendarService_ListTrash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTrashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarServiceServer).ListTrash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/calendar.CalendarService/ListTrash",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarServiceServer).ListTrash(ctx, req.(*ListTrashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CalendarService_RestoreEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EventId)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarServiceServer).RestoreEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/calendar.CalendarService/RestoreEvent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarServiceServer).RestoreEvent(ctx, req.(*EventId))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CalendarService_PurgeEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EventId)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarServiceServer).PurgeEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/calendar.CalendarService/PurgeEvent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarServiceServer).PurgeEvent(ctx, req.(*EventId))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CalendarService_GetEventHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EventId)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarServiceServer).GetEventHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/calendar.CalendarService/GetEventHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarServiceServer).GetEventHistory(ctx, req.(*EventId))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _CalendarService_ListEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListEventsRequest)
 	if err := dec(in); err != nil {
@@ -182,6 +505,132 @@ func _CalendarService_ListEvents_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CalendarService_ListEventRange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListEventRangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarServiceServer).ListEventRange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/calendar.CalendarService/ListEventRange",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarServiceServer).ListEventRange(ctx, req.(*ListEventRangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CalendarService_SearchEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarServiceServer).SearchEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/calendar.CalendarService/SearchEvents",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarServiceServer).SearchEvents(ctx, req.(*SearchEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CalendarService_AddAttendee_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttendeeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarServiceServer).AddAttendee(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/calendar.CalendarService/AddAttendee",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarServiceServer).AddAttendee(ctx, req.(*AttendeeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CalendarService_RemoveAttendee_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttendeeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarServiceServer).RemoveAttendee(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/calendar.CalendarService/RemoveAttendee",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarServiceServer).RemoveAttendee(ctx, req.(*AttendeeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CalendarService_RespondToEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttendeeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarServiceServer).RespondToEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/calendar.CalendarService/RespondToEvent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarServiceServer).RespondToEvent(ctx, req.(*AttendeeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CalendarService_GetFreeBusy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FreeBusyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarServiceServer).GetFreeBusy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/calendar.CalendarService/GetFreeBusy",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarServiceServer).GetFreeBusy(ctx, req.(*FreeBusyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CalendarService_FindSlots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindSlotsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarServiceServer).FindSlots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/calendar.CalendarService/FindSlots",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarServiceServer).FindSlots(ctx, req.(*FindSlotsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CalendarService_ServiceDesc is the grpc.ServiceDesc for CalendarService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -193,18 +642,70 @@ var CalendarService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetEvent",
 			Handler:    _CalendarService_GetEvent_Handler,
 		},
+		{
+			MethodName: "CreateEvent",
+			Handler:    _CalendarService_CreateEvent_Handler,
+		},
 		{
 			MethodName: "PutEvent",
 			Handler:    _CalendarService_PutEvent_Handler,
 		},
+		{
+			MethodName: "UpdateEvent",
+			Handler:    _CalendarService_UpdateEvent_Handler,
+		},
 		{
 			MethodName: "DeleteEvent",
 			Handler:    _CalendarService_DeleteEvent_Handler,
 		},
+		{
+			MethodName: "ListTrash",
+			Handler:    _CalendarService_ListTrash_Handler,
+		},
+		{
+			MethodName: "RestoreEvent",
+			Handler:    _CalendarService_RestoreEvent_Handler,
+		},
+		{
+			MethodName: "PurgeEvent",
+			Handler:    _CalendarService_PurgeEvent_Handler,
+		},
+		{
+			MethodName: "GetEventHistory",
+			Handler:    _CalendarService_GetEventHistory_Handler,
+		},
 		{
 			MethodName: "ListEvents",
 			Handler:    _CalendarService_ListEvents_Handler,
 		},
+		{
+			MethodName: "ListEventRange",
+			Handler:    _CalendarService_ListEventRange_Handler,
+		},
+		{
+			MethodName: "SearchEvents",
+			Handler:    _CalendarService_SearchEvents_Handler,
+		},
+		{
+			MethodName: "AddAttendee",
+			Handler:    _CalendarService_AddAttendee_Handler,
+		},
+		{
+			MethodName: "RemoveAttendee",
+			Handler:    _CalendarService_RemoveAttendee_Handler,
+		},
+		{
+			MethodName: "RespondToEvent",
+			Handler:    _CalendarService_RespondToEvent_Handler,
+		},
+		{
+			MethodName: "GetFreeBusy",
+			Handler:    _CalendarService_GetFreeBusy_Handler,
+		},
+		{
+			MethodName: "FindSlots",
+			Handler:    _CalendarService_FindSlots_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "calendar.proto",
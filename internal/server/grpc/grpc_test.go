@@ -7,12 +7,19 @@ import (
 	"time"
 
 	"github.com/VladNF/calendar/internal/app"
+	"github.com/VladNF/calendar/internal/auth"
 	"github.com/VladNF/calendar/internal/common"
+	"github.com/VladNF/calendar/internal/models"
 	"github.com/VladNF/calendar/internal/server/grpc/gen"
 	"github.com/VladNF/calendar/internal/storage"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -52,7 +59,7 @@ func TestGrpcServer(t *testing.T) {
 	startDate := time.Date(2021, 1, 1, 8, 0, 0, 0, time.UTC)
 	startTime := startDate.Add(8 * time.Hour)
 	t.Run("Get Event", func(t *testing.T) {
-		event, err := grpcServer.app.CreateEvent(ctx, "", "today event", startTime, startTime.Add(time.Hour), "test")
+		event, err := grpcServer.app.CreateEvent(ctx, "", "today event", startTime, startTime.Add(time.Hour), "test", false)
 		require.NoError(t, err)
 
 		expected := gen.Event{
@@ -63,6 +70,8 @@ func TestGrpcServer(t *testing.T) {
 			OwnerId:     event.OwnerID,
 			StartsAt:    timestamppb.New(event.StartsAt),
 			Title:       event.Title,
+			Version:     1,
+			UpdatedAt:   timestamppb.New(event.UpdatedAt),
 		}
 		r, err := tc.GetEvent(ctx, &gen.EventId{Id: event.ID})
 		require.NoError(t, err)
@@ -73,18 +82,88 @@ func TestGrpcServer(t *testing.T) {
 	})
 
 	t.Run("Delete Event", func(t *testing.T) {
-		event, err := grpcServer.app.CreateEvent(ctx, "", "today event", startTime, startTime.Add(time.Hour), "test")
+		startTime := startTime.Add(time.Hour)
+		event, err := grpcServer.app.CreateEvent(ctx, "", "today event", startTime, startTime.Add(time.Hour), "test", false)
 		require.NoError(t, err)
 
+		_, err = tc.DeleteEvent(ctx, &gen.EventId{Id: event.ID, Version: event.Version + 1})
+		require.Equal(t, codes.Aborted, status.Code(err))
 		_, err = tc.DeleteEvent(ctx, &gen.EventId{Id: event.ID})
 		require.NoError(t, err)
 
 		_, err = tc.GetEvent(ctx, &gen.EventId{Id: event.ID})
 		require.NotNil(t, err)
+		_, err = tc.PutEvent(ctx, newEventMessage(event))
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("Trash", func(t *testing.T) {
+		event, err := grpcServer.app.CreateEvent(ctx, "", "trashed", startTime, startTime.Add(time.Hour), "trasher", false)
+		require.NoError(t, err)
+		_, err = tc.DeleteEvent(ctx, &gen.EventId{Id: event.ID})
+		require.NoError(t, err)
+
+		r, err := tc.ListTrash(ctx, &gen.ListTrashRequest{OwnerId: "trasher"})
+		require.NoError(t, err)
+		require.Len(t, r.Events, 1)
+		require.Equal(t, event.ID, r.Events[0].Id)
+		require.NotNil(t, r.Events[0].DeletedAt)
+
+		_, err = tc.RestoreEvent(ctx, &gen.EventId{Id: event.ID, Version: 1})
+		require.Equal(t, codes.Aborted, status.Code(err))
+		restored, err := tc.RestoreEvent(ctx, &gen.EventId{Id: event.ID})
+		require.NoError(t, err)
+		require.Nil(t, restored.DeletedAt)
+		require.EqualValues(t, 3, restored.Version)
+
+		_, err = tc.PurgeEvent(ctx, &gen.EventId{Id: event.ID})
+		require.Equal(t, codes.NotFound, status.Code(err))
+		_, err = tc.DeleteEvent(ctx, &gen.EventId{Id: event.ID})
+		require.NoError(t, err)
+		_, err = tc.PurgeEvent(ctx, &gen.EventId{Id: event.ID})
+		require.NoError(t, err)
+		r, err = tc.ListTrash(ctx, &gen.ListTrashRequest{OwnerId: "trasher"})
+		require.NoError(t, err)
+		require.Len(t, r.Events, 0)
+
+		history, err := tc.GetEventHistory(ctx, &gen.EventId{Id: event.ID})
+		require.NoError(t, err)
+		operations := make([]string, 0, len(history.Records))
+		for _, record := range history.Records {
+			operations = append(operations, record.Operation)
+		}
+		require.Equal(t, []string{"create", "delete", "restore", "delete", "purge"}, operations)
+		require.Nil(t, history.Records[0].Before)
+		require.NotNil(t, history.Records[1].After.DeletedAt)
+		require.Nil(t, history.Records[2].After.DeletedAt)
+		require.Nil(t, history.Records[4].After)
+		_, err = tc.GetEventHistory(ctx, &gen.EventId{Id: "never-stored"})
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("Create Event", func(t *testing.T) {
+		startTime := startTime.Add(3 * time.Hour)
+		msg := &gen.Event{
+			Id:       "grpc-created",
+			Title:    "created event",
+			OwnerId:  "test",
+			StartsAt: timestamppb.New(startTime),
+			EndsAt:   timestamppb.New(startTime.Add(30 * time.Minute)),
+		}
+		r, err := tc.CreateEvent(ctx, msg)
+		require.NoError(t, err)
+		require.Equal(t, "grpc-created", r.Id)
+		require.EqualValues(t, 1, r.Version)
+
+		_, err = tc.CreateEvent(ctx, msg)
+		require.Equal(t, codes.AlreadyExists, status.Code(err))
+		_, err = tc.DeleteEvent(ctx, &gen.EventId{Id: r.Id})
+		require.NoError(t, err)
 	})
 
 	t.Run("Update Event", func(t *testing.T) {
-		event, err := grpcServer.app.CreateEvent(ctx, "", "today event", startTime, startTime.Add(time.Hour), "test")
+		startTime := startTime.Add(2 * time.Hour)
+		event, err := grpcServer.app.CreateEvent(ctx, "", "today event", startTime, startTime.Add(time.Hour), "test", false)
 		require.NoError(t, err)
 
 		expected := gen.Event{
@@ -104,6 +183,145 @@ func TestGrpcServer(t *testing.T) {
 		require.Equal(t, r.Notes, expected.Notes)
 	})
 
+	t.Run("Update Event Fields", func(t *testing.T) {
+		startTime := startTime.Add(5 * time.Hour)
+		event, err := grpcServer.app.CreateEvent(ctx, "", "masked event", startTime, startTime.Add(time.Hour), "mask", false)
+		require.NoError(t, err)
+		event.Notes = "kept"
+		require.NoError(t, grpcServer.app.UpdateEvent(ctx, event, false))
+
+		msg := &gen.Event{
+			Id:       event.ID,
+			Title:    "ignored",
+			StartsAt: timestamppb.New(startTime.Add(30 * time.Minute)),
+			EndsAt:   timestamppb.New(startTime.Add(90 * time.Minute)),
+			Version:  event.Version,
+		}
+		mask := &fieldmaskpb.FieldMask{Paths: []string{"starts_at", "ends_at"}}
+		r, err := tc.UpdateEvent(ctx, &gen.UpdateEventRequest{Event: msg, UpdateMask: mask})
+		require.NoError(t, err)
+		require.Equal(t, "masked event", r.Title)
+		require.Equal(t, "kept", r.Notes)
+		require.True(t, startTime.Add(30*time.Minute).Equal(r.StartsAt.AsTime()))
+		require.EqualValues(t, 3, r.Version)
+
+		_, err = tc.UpdateEvent(ctx, &gen.UpdateEventRequest{Event: msg, UpdateMask: mask})
+		require.Equal(t, codes.Aborted, status.Code(err))
+		msg.Version = 0
+		for _, paths := range [][]string{{"ends_at"}, {"id"}, {}} {
+			msg.EndsAt = nil
+			mask := &fieldmaskpb.FieldMask{Paths: paths}
+			_, err = tc.UpdateEvent(ctx, &gen.UpdateEventRequest{Event: msg, UpdateMask: mask})
+			require.Equal(t, codes.InvalidArgument, status.Code(err), paths)
+		}
+		msg.Id = "missing"
+		_, err = tc.UpdateEvent(ctx, &gen.UpdateEventRequest{Event: msg, UpdateMask: mask})
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("Busy Slot", func(t *testing.T) {
+		startTime := startTime.Add(4 * time.Hour)
+		event, err := grpcServer.app.CreateEvent(ctx, "", "busy event", startTime, startTime.Add(time.Hour), "test", false)
+		require.NoError(t, err)
+
+		overlapping := newEventMessage(event)
+		overlapping.Id = ""
+		overlapping.StartsAt = timestamppb.New(startTime.Add(30 * time.Minute))
+		_, err = tc.CreateEvent(ctx, overlapping)
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+		// PutEvent does not create events so that retries do not duplicate them
+		overlapping.AllowOverlap = true
+		_, err = tc.PutEvent(ctx, overlapping)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+		_, err = tc.CreateEvent(ctx, overlapping)
+		require.NoError(t, err)
+	})
+
+	t.Run("Attendees", func(t *testing.T) {
+		startTime := startTime.Add(5 * time.Hour)
+		event, err := grpcServer.app.CreateEvent(ctx, "", "meeting", startTime, startTime.Add(time.Hour), "test", false)
+		require.NoError(t, err)
+
+		request := &gen.AttendeeRequest{EventId: event.ID, UserId: "bob"}
+		r, err := tc.AddAttendee(ctx, request)
+		require.NoError(t, err)
+		require.Len(t, r.Attendees, 1)
+		require.Equal(t, gen.Attendee_NEEDS_ACTION, r.Attendees[0].Status)
+
+		request.Status = gen.Attendee_ACCEPTED
+		r, err = tc.RespondToEvent(ctx, request)
+		require.NoError(t, err)
+
+		msg := newEventMessage(event)
+		msg.Title = "renamed meeting"
+		msg.Version = 1
+		_, err = tc.PutEvent(ctx, msg)
+		require.Equal(t, codes.Aborted, status.Code(err))
+		msg.Version = r.Version
+		r, err = tc.PutEvent(ctx, msg)
+		require.NoError(t, err)
+		require.Len(t, r.Attendees, 1)
+		require.Equal(t, gen.Attendee_ACCEPTED, r.Attendees[0].Status)
+
+		r, err = tc.RemoveAttendee(ctx, request)
+		require.NoError(t, err)
+		require.Len(t, r.Attendees, 0)
+		_, err = tc.RemoveAttendee(ctx, request)
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("Free Busy", func(t *testing.T) {
+		startTime := startTime.AddDate(0, 0, 1)
+		_, err := grpcServer.app.CreateEvent(ctx, "", "review", startTime, startTime.Add(time.Hour), "fb-alice", false)
+		require.NoError(t, err)
+
+		r, err := tc.GetFreeBusy(ctx, &gen.FreeBusyRequest{
+			OwnerIds: []string{"fb-alice", "fb-bob"},
+			From:     timestamppb.New(startTime.Add(-time.Hour)),
+			To:       timestamppb.New(startTime.Add(3 * time.Hour)),
+			MinFree:  1800,
+		})
+		require.NoError(t, err)
+		require.Len(t, r.Busy, 2)
+		require.Len(t, r.Busy[0].Intervals, 1)
+		require.True(t, r.Busy[0].Intervals[0].End.AsTime().Equal(startTime.Add(time.Hour)))
+		require.Len(t, r.Busy[1].Intervals, 0)
+		require.Len(t, r.Free, 2)
+
+		_, err = tc.GetFreeBusy(ctx, &gen.FreeBusyRequest{From: timestamppb.New(startTime), To: timestamppb.New(startTime)})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Find Slots", func(t *testing.T) {
+		day := time.Date(2021, 6, 2, 0, 0, 0, 0, time.UTC)
+		r, err := tc.FindSlots(ctx, &gen.FindSlotsRequest{
+			Attendees: []string{"slot-alice"},
+			Duration:  3600,
+			From:      timestamppb.New(day),
+			To:        timestamppb.New(day.AddDate(0, 0, 1)),
+			WorkStart: "09:00",
+			WorkEnd:   "10:00",
+			Timezone:  "Europe/Berlin",
+		})
+		require.NoError(t, err)
+		require.Len(t, r.Slots, 1)
+		require.True(t, r.Slots[0].Start.AsTime().Equal(day.Add(7*time.Hour)))
+
+		r, err = tc.FindSlots(ctx, &gen.FindSlotsRequest{
+			Attendees: []string{"slot-alice"},
+			Duration:  900,
+			From:      timestamppb.New(day),
+			To:        timestamppb.New(day.AddDate(0, 0, 2)),
+			Limit:     10 * models.MaxSlotLimit,
+		})
+		require.NoError(t, err)
+		require.Len(t, r.Slots, models.MaxSlotLimit)
+
+		_, err = tc.FindSlots(ctx, &gen.FindSlotsRequest{Attendees: []string{"slot-alice"}, WorkStart: "9 am"})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
 	t.Run("List Events", func(t *testing.T) {
 		startTime := startTime.AddDate(0, 1, 0)
 		event, err := grpcServer.app.CreateEvent(
@@ -112,6 +330,7 @@ func TestGrpcServer(t *testing.T) {
 			startTime,
 			startTime.Add(time.Hour),
 			"test",
+			false,
 		)
 		require.NoError(t, err)
 
@@ -122,6 +341,8 @@ func TestGrpcServer(t *testing.T) {
 			OwnerId:     event.OwnerID,
 			StartsAt:    timestamppb.New(event.StartsAt),
 			Title:       event.Title,
+			Version:     1,
+			UpdatedAt:   timestamppb.New(event.UpdatedAt),
 		}
 
 		request := &gen.ListEventsRequest{
@@ -132,16 +353,149 @@ func TestGrpcServer(t *testing.T) {
 		require.NoError(t, err)
 		require.Len(t, r.Events, 1)
 		require.Equal(t, r.Events[0].String(), expected.String())
+
+		// the event is on Monday, the week of the next Sunday has it when weeks start on Monday
+		request = &gen.ListEventsRequest{
+			Agenda:    gen.ListEventsRequest_WEEKLY,
+			StartFrom: timestamppb.New(startDate.AddDate(0, 1, 6)),
+		}
+		r, err = tc.ListEvents(ctx, request)
+		require.NoError(t, err)
+		require.Len(t, r.Events, 0)
+		request.WeekStart = "MO"
+		r, err = tc.ListEvents(ctx, request)
+		require.NoError(t, err)
+		require.Len(t, r.Events, 1)
+
+		request = &gen.ListEventsRequest{Agenda: gen.ListEventsRequest_WEEKLY, IsoWeek: "2021-W05"}
+		r, err = tc.ListEvents(ctx, request)
+		require.NoError(t, err)
+		require.Len(t, r.Events, 1)
+		request.Agenda = gen.ListEventsRequest_DAILY
+		_, err = tc.ListEvents(ctx, request)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Search Events", func(t *testing.T) {
+		startTime := startTime.AddDate(0, 6, 0)
+		_, err := grpcServer.app.CreateEvent(ctx, "", "Budget review", startTime, startTime.Add(time.Hour), "test", false)
+		require.NoError(t, err)
+
+		r, err := tc.SearchEvents(ctx, &gen.SearchEventsRequest{Text: "review budget", OwnerId: "test"})
+		require.NoError(t, err)
+		require.Len(t, r.Events, 1)
+		require.Equal(t, "Budget review", r.Events[0].Title)
+
+		_, err = tc.SearchEvents(ctx, &gen.SearchEventsRequest{Text: "?"})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("List Event Range", func(t *testing.T) {
+		request := &gen.ListEventRangeRequest{
+			From:     timestamppb.New(startDate),
+			To:       timestamppb.New(startDate.AddDate(0, 2, 0)),
+			OwnerId:  "test",
+			PageSize: 1,
+		}
+		r, err := tc.ListEventRange(ctx, request)
+		require.NoError(t, err)
+		require.Len(t, r.Events, 1)
+		require.NotEmpty(t, r.NextPageToken)
+
+		request.PageToken = r.NextPageToken
+		next, err := tc.ListEventRange(ctx, request)
+		require.NoError(t, err)
+		require.Len(t, next.Events, 1)
+		require.NotEqual(t, r.Events[0].Id, next.Events[0].Id)
+
+		request.To = request.From
+		_, err = tc.ListEventRange(ctx, request)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
 	})
 }
 
+func TestGrpcAuth(t *testing.T) {
+	ctx := context.Background()
+	s := makeServer()
+	s.verifier, _ = auth.NewVerifier(common.AuthConf{HMACSecret: "secret"})
+	listener := bufconn.Listen(bufSize)
+	server := grpc.NewServer(s.serverOptions()...)
+	gen.RegisterCalendarServiceServer(server, s)
+	go server.Serve(listener) //nolint:errcheck // it fails after Stop only
+	defer server.Stop()
+	dialer := func(context.Context, string) (net.Conn, error) { return listener.Dial() }
+	conn, err := grpc.DialContext(ctx, "bufnet", grpc.WithContextDialer(dialer), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+	tc := gen.NewCalendarServiceClient(conn)
+
+	startTime := time.Date(2021, 1, 1, 8, 0, 0, 0, time.UTC)
+	msg := &gen.Event{
+		Title:    "today event",
+		OwnerId:  "someone else",
+		StartsAt: timestamppb.New(startTime),
+		EndsAt:   timestamppb.New(startTime.Add(time.Hour)),
+	}
+	t.Run("No Token", func(t *testing.T) {
+		_, err := tc.CreateEvent(ctx, msg)
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("Bad Token", func(t *testing.T) {
+		_, err := tc.CreateEvent(withToken(ctx, t, "alice", "wrong secret"), msg)
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("Owner From Token", func(t *testing.T) {
+		r, err := tc.CreateEvent(withToken(ctx, t, "alice", "secret"), msg)
+		require.NoError(t, err)
+		require.Equal(t, "alice", r.OwnerId)
+	})
+
+	t.Run("Edit Rights", func(t *testing.T) {
+		startTime := startTime.Add(time.Hour)
+		event, err := s.app.CreateEvent(ctx, "", "alice event", startTime, startTime.Add(time.Hour), "alice", false)
+		require.NoError(t, err)
+		renamed := newEventMessage(event)
+		renamed.Title = "renamed event"
+
+		bob := withToken(ctx, t, "bob", "secret")
+		_, err = tc.PutEvent(bob, renamed)
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+		_, err = tc.DeleteEvent(bob, &gen.EventId{Id: event.ID})
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+		assistant := withToken(ctx, t, "carol", "secret", "alice")
+		r, err := tc.PutEvent(assistant, renamed)
+		require.NoError(t, err)
+		require.Equal(t, "alice", r.OwnerId)
+		require.Equal(t, "renamed event", r.Title)
+		_, err = tc.DeleteEvent(assistant, &gen.EventId{Id: event.ID})
+		require.NoError(t, err)
+	})
+}
+
+// withToken returns the context sending a bearer token signed with the secret for the subject
+func withToken(ctx context.Context, t *testing.T, subject, secret string, grants ...string) context.Context {
+	claims := jwt.MapClaims{"sub": subject, "exp": time.Now().Add(time.Hour).Unix(), "grants": grants}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	require.NoError(t, err)
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
+
 func makeServer() *GRPCServer {
 	log := common.NewLogger("debug", "")
 
-	eventsRepo, err := storage.NewStorage("in-memory")
+	conf := common.StorageConf{Kind: "in-memory"}
+	eventsRepo, err := storage.NewStorage(conf)
 	if err != nil {
 		log.Fatalf("storage was not created: %v", err)
 	}
+	audit, err := storage.NewAuditStorage(conf, eventsRepo)
+	if err != nil {
+		log.Fatalf("audit log was not created: %v", err)
+	}
 	calendar := app.New(log, eventsRepo)
-	return NewServer("", "", log, calendar)
+	calendar.SetAudit(audit)
+	return NewServer("", "", log, calendar, nil)
 }
@@ -0,0 +1,92 @@
+package servergrpc
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/VladNF/calendar/internal/models"
+	"github.com/VladNF/calendar/internal/server/grpc/gen"
+)
+
+// applyFieldMask sets the fields of the event named by the paths to their values in the message,
+// a field the message lacks is cleared, exdates are kept when only the recurrence rule changes
+func applyFieldMask(event *models.Event, msg *gen.Event, paths []string) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("%w: update_mask has no paths", models.ErrValueError)
+	}
+	var exDates []time.Time
+	var setExDates bool
+	for _, path := range paths {
+		switch path {
+		case "title":
+			event.Title = msg.Title
+		case "starts_at", "ends_at":
+			at := msg.StartsAt
+			if path == "ends_at" {
+				at = msg.EndsAt
+			}
+			if at == nil {
+				return fmt.Errorf("%w: %s may not be cleared", models.ErrValueError, path)
+			}
+			if path == "starts_at" {
+				event.StartsAt = at.AsTime()
+			} else {
+				event.EndsAt = at.AsTime()
+			}
+		case "notes":
+			event.Notes = msg.Notes
+		case "owner_id":
+			if msg.OwnerId == "" {
+				return fmt.Errorf("%w: owner_id may not be cleared", models.ErrValueError)
+			}
+			event.OwnerID = msg.OwnerId
+		case "alert_before":
+			event.AlertBefore = time.Duration(msg.AlertBefore * 1_000_000_000)
+		case "all_day":
+			event.AllDay = msg.AllDay
+		case "timezone":
+			event.Timezone = nil
+			if msg.Timezone != "" {
+				timezone, err := models.LoadTimezone(msg.Timezone)
+				if err != nil {
+					return err
+				}
+				event.Timezone = timezone
+			}
+		case "recurrence":
+			if msg.Recurrence == "" {
+				event.Recurrence = nil
+				continue
+			}
+			recurrence, err := models.ParseRRule(msg.Recurrence)
+			if err != nil {
+				return err
+			}
+			if event.IsRecurring() {
+				recurrence.ExDates = event.Recurrence.ExDates
+			}
+			event.Recurrence = recurrence
+		case "exdates":
+			setExDates = true
+			for _, d := range msg.Exdates {
+				exDates = append(exDates, d.AsTime())
+			}
+		default:
+			return fmt.Errorf("%w: %q of update_mask can not be changed", models.ErrValueError, path)
+		}
+	}
+	if !setExDates {
+		return nil
+	}
+	if !event.IsRecurring() {
+		if len(exDates) > 0 {
+			return fmt.Errorf("%w: exdates need a recurrence", models.ErrValueError)
+		}
+		return nil
+	}
+	// the stored event may share its recurrence, so it is copied rather than changed
+	recurrence := *event.Recurrence
+	recurrence.ExDates = exDates
+	event.Recurrence = &recurrence
+	return nil
+}
@@ -7,15 +7,19 @@ import (
 	"time"
 
 	"github.com/VladNF/calendar/internal/app"
+	"github.com/VladNF/calendar/internal/auth"
 	"github.com/VladNF/calendar/internal/common"
 	"github.com/VladNF/calendar/internal/models"
 	"github.com/VladNF/calendar/internal/server/grpc/gen"
 	"github.com/golang/protobuf/ptypes/empty"
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
 	grpc_logrus "github.com/grpc-ecosystem/go-grpc-middleware/logging/logrus"
 	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -24,19 +28,31 @@ func init() {
 }
 
 type GRPCServer struct {
-	app    *app.App
-	host   string
-	port   string
-	log    common.Logger
-	server *grpc.Server
+	app      *app.App
+	host     string
+	port     string
+	log      common.Logger
+	server   *grpc.Server
+	verifier *auth.Verifier
 }
 
-func (s *GRPCServer) GetEvent(ctx context.Context, id *gen.EventId) (*gen.Event, error) {
-	e, err := s.app.GetEvent(ctx, id.GetId())
-	if err != nil {
-		return nil, err
+var (
+	statusFromModel = map[models.ResponseStatus]gen.Attendee_Status{
+		models.NeedsAction: gen.Attendee_NEEDS_ACTION,
+		models.Accepted:    gen.Attendee_ACCEPTED,
+		models.Declined:    gen.Attendee_DECLINED,
+		models.Tentative:   gen.Attendee_TENTATIVE,
+	}
+	statusToModel = map[gen.Attendee_Status]models.ResponseStatus{
+		gen.Attendee_NEEDS_ACTION: models.NeedsAction,
+		gen.Attendee_ACCEPTED:     models.Accepted,
+		gen.Attendee_DECLINED:     models.Declined,
+		gen.Attendee_TENTATIVE:    models.Tentative,
 	}
-	return &gen.Event{
+)
+
+func newEventMessage(e *models.Event) *gen.Event {
+	msg := &gen.Event{
 		AlertBefore: int64(e.AlertBefore.Seconds()),
 		EndsAt:      timestamppb.New(e.EndsAt),
 		Id:          e.ID,
@@ -44,85 +60,388 @@ func (s *GRPCServer) GetEvent(ctx context.Context, id *gen.EventId) (*gen.Event,
 		OwnerId:     e.OwnerID,
 		StartsAt:    timestamppb.New(e.StartsAt),
 		Title:       e.Title,
-	}, nil
+		AllDay:      e.AllDay,
+	}
+	if e.IsRecurring() {
+		msg.Recurrence = e.Recurrence.String()
+		for _, d := range e.Recurrence.ExDates {
+			msg.Exdates = append(msg.Exdates, timestamppb.New(d))
+		}
+	}
+	if !e.OccurrenceStart.IsZero() {
+		msg.OccurrenceStart = timestamppb.New(e.OccurrenceStart)
+	}
+	if e.Timezone != nil {
+		msg.Timezone = e.Timezone.String()
+	}
+	msg.Version = e.Version
+	if !e.UpdatedAt.IsZero() {
+		msg.UpdatedAt = timestamppb.New(e.UpdatedAt)
+	}
+	if e.IsDeleted() {
+		msg.DeletedAt = timestamppb.New(e.DeletedAt)
+	}
+	for _, a := range e.Attendees {
+		msg.Attendees = append(msg.Attendees, &gen.Attendee{UserId: a.UserID, Status: statusFromModel[a.Status]})
+	}
+	return msg
 }
 
-func (s *GRPCServer) PutEvent(ctx context.Context, event *gen.Event) (*gen.Event, error) {
-	m, err := models.NewEvent(event.Id, event.Title, event.StartsAt.AsTime(), event.EndsAt.AsTime(), event.OwnerId)
+func newEventModel(event *gen.Event) (*models.Event, error) {
+	newEvent := models.NewEvent
+	if event.AllDay {
+		newEvent = models.NewAllDayEvent
+	}
+	m, err := newEvent(event.Id, event.Title, event.StartsAt.AsTime(), event.EndsAt.AsTime(), event.OwnerId)
 	if err != nil {
 		return nil, err
 	}
 	m.AlertBefore = time.Duration(event.AlertBefore * 1_000_000_000)
 	m.Notes = event.Notes
-	if err = s.app.UpdateEvent(ctx, m); err != nil {
-		return nil, err
+	if event.Id != "" {
+		// an event without id is a new one whatever version the message has
+		m.Version = event.Version
+	}
+	if event.Timezone != "" {
+		if m.Timezone, err = models.LoadTimezone(event.Timezone); err != nil {
+			return nil, err
+		}
+	}
+	if event.Recurrence != "" {
+		if m.Recurrence, err = models.ParseRRule(event.Recurrence); err != nil {
+			return nil, err
+		}
+		for _, d := range event.Exdates {
+			m.Recurrence.ExDates = append(m.Recurrence.ExDates, d.AsTime())
+		}
+	}
+	return m, nil
+}
+
+func (s *GRPCServer) GetEvent(ctx context.Context, id *gen.EventId) (*gen.Event, error) {
+	e, err := s.app.GetEvent(ctx, id.GetId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return newEventMessage(e), nil
+}
+
+// CreateEvent stores a new event, an event without id gets a new one
+func (s *GRPCServer) CreateEvent(ctx context.Context, event *gen.Event) (*gen.Event, error) {
+	m, err := newEventModel(event)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	m.Version = 0
+	if err = s.app.AddEvent(ctx, m, event.AllowOverlap); err != nil {
+		return nil, statusError(err)
+	}
+	return newEventMessage(m), nil
+}
+
+// PutEvent replaces the event having the id, events are created by CreateEvent only
+func (s *GRPCServer) PutEvent(ctx context.Context, event *gen.Event) (*gen.Event, error) {
+	if event.Id == "" {
+		return nil, statusError(fmt.Errorf("%w: event id is required", models.ErrValueError))
+	}
+	m, err := newEventModel(event)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	if err = s.app.UpdateEvent(ctx, m, event.AllowOverlap); err != nil {
+		return nil, statusError(err)
 	}
-	return event, nil
+	return newEventMessage(m), nil
 }
 
+// UpdateEvent changes the fields of the update mask, a non-zero version of the event must be the stored one
+func (s *GRPCServer) UpdateEvent(ctx context.Context, req *gen.UpdateEventRequest) (*gen.Event, error) {
+	event := req.GetEvent()
+	if event.GetId() == "" {
+		return nil, statusError(fmt.Errorf("%w: event id is required", models.ErrValueError))
+	}
+	apply := func(m *models.Event) error {
+		return applyFieldMask(m, event, req.GetUpdateMask().GetPaths())
+	}
+	m, err := s.app.PatchEvent(ctx, event.Id, event.Version, apply, event.AllowOverlap)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return newEventMessage(m), nil
+}
+
+// DeleteEvent moves the event into the trash, a non-zero version of the request must be the version of the event
 func (s *GRPCServer) DeleteEvent(ctx context.Context, id *gen.EventId) (*empty.Empty, error) {
 	e, err := s.app.GetEvent(ctx, id.GetId())
 	if err != nil {
-		return nil, err
+		return nil, statusError(err)
+	}
+	if id.Version != 0 && id.Version != e.Version {
+		return nil, statusError(fmt.Errorf("%w: event %s is at version %d", models.ErrConflict, e.ID, e.Version))
 	}
 
 	if err := s.app.DeleteEvent(ctx, e); err != nil {
-		return nil, err
+		return nil, statusError(err)
 	}
 	return &empty.Empty{}, nil
 }
 
+func (s *GRPCServer) AddAttendee(ctx context.Context, request *gen.AttendeeRequest) (*gen.Event, error) {
+	e, err := s.app.AddAttendee(ctx, request.EventId, request.UserId)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return newEventMessage(e), nil
+}
+
+func (s *GRPCServer) RemoveAttendee(ctx context.Context, request *gen.AttendeeRequest) (*gen.Event, error) {
+	e, err := s.app.RemoveAttendee(ctx, request.EventId, request.UserId)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return newEventMessage(e), nil
+}
+
+func (s *GRPCServer) RespondToEvent(ctx context.Context, request *gen.AttendeeRequest) (*gen.Event, error) {
+	rsvp, ok := statusToModel[request.Status]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "grpc: invalid response status %s", request.Status)
+	}
+	e, err := s.app.RespondToEvent(ctx, request.EventId, request.UserId, rsvp)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return newEventMessage(e), nil
+}
+
+func newIntervalMessages(intervals []models.Interval) []*gen.Interval {
+	msgs := make([]*gen.Interval, 0, len(intervals))
+	for _, i := range intervals {
+		msgs = append(msgs, &gen.Interval{Start: timestamppb.New(i.Start), End: timestamppb.New(i.End)})
+	}
+	return msgs
+}
+
+func (s *GRPCServer) GetFreeBusy(ctx context.Context, request *gen.FreeBusyRequest) (*gen.FreeBusyResponse, error) {
+	minFree := time.Duration(request.MinFree) * time.Second
+	freeBusy, err := s.app.GetFreeBusy(ctx, request.OwnerIds, request.From.AsTime(), request.To.AsTime(), minFree)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	response := &gen.FreeBusyResponse{Free: newIntervalMessages(freeBusy.Free)}
+	for _, owner := range request.OwnerIds {
+		response.Busy = append(response.Busy, &gen.FreeBusyResponse_OwnerBusy{
+			OwnerId:   owner,
+			Intervals: newIntervalMessages(freeBusy.Busy[owner]),
+		})
+	}
+	return response, nil
+}
+
+func (s *GRPCServer) FindSlots(ctx context.Context, request *gen.FindSlotsRequest) (*gen.FindSlotsResponse, error) {
+	query := models.SlotQuery{
+		Attendees: request.Attendees,
+		Duration:  time.Duration(request.Duration) * time.Second,
+		From:      request.From.AsTime(),
+		To:        request.To.AsTime(),
+		Limit:     int(request.Limit),
+	}
+	if query.Limit == 0 {
+		query.Limit = models.DefaultSlotLimit
+	}
+	err := query.SetWorkingHours(request.WorkStart, request.WorkEnd, request.WorkDays, request.Timezone)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	slots, err := s.app.FindSlots(ctx, query)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &gen.FindSlotsResponse{Slots: newIntervalMessages(slots)}, nil
+}
+
 func (s *GRPCServer) ListEvents(ctx context.Context, request *gen.ListEventsRequest) (*gen.ListEventsResponse, error) {
+	start, err := models.InTimezone(request.StartFrom.AsTime(), request.Timezone)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	weekStart := s.app.WeekStart()
+	if request.WeekStart != "" {
+		if weekStart, err = models.ParseWeekday(request.WeekStart); err != nil {
+			return nil, statusError(err)
+		}
+	}
+	if request.IsoWeek != "" && request.Agenda != gen.ListEventsRequest_WEEKLY {
+		return nil, status.Error(codes.InvalidArgument, "grpc: iso_week is only used by the weekly agenda")
+	}
+
 	var events []*models.Event
-	var err error
 	switch request.Agenda {
 	case gen.ListEventsRequest_DAILY:
-		events, err = s.app.GetDailyAgenda(ctx, request.StartFrom.AsTime())
+		events, err = s.app.GetDailyAgenda(ctx, start, request.OwnerId)
 	case gen.ListEventsRequest_WEEKLY:
-		events, err = s.app.GetWeeklyAgenda(ctx, request.StartFrom.AsTime())
+		if request.IsoWeek != "" {
+			events, err = s.app.GetISOWeekAgenda(ctx, request.IsoWeek, start.Location(), request.OwnerId)
+			break
+		}
+		events, err = s.app.GetWeeklyAgenda(ctx, start, weekStart, request.OwnerId)
 	case gen.ListEventsRequest_MONTHLY:
-		events, err = s.app.GetMonthlyAgenda(ctx, request.StartFrom.AsTime())
+		events, err = s.app.GetMonthlyAgenda(ctx, start, request.OwnerId)
 	default:
-		return nil, fmt.Errorf("http: invalid agenda type %s", request.Agenda)
+		return nil, status.Errorf(codes.InvalidArgument, "grpc: invalid agenda type %s", request.Agenda)
 	}
 
 	if err != nil {
-		return nil, err
+		return nil, statusError(err)
 	}
 
 	result := make([]*gen.Event, 0, len(events))
 	for _, e := range events {
-		result = append(result, &gen.Event{
-			AlertBefore: int64(e.AlertBefore.Seconds()),
-			EndsAt:      timestamppb.New(e.EndsAt),
-			Id:          e.ID,
-			Notes:       e.Notes,
-			OwnerId:     e.OwnerID,
-			StartsAt:    timestamppb.New(e.StartsAt),
-			Title:       e.Title,
-		})
+		result = append(result, newEventMessage(e))
 	}
 	return &gen.ListEventsResponse{Events: result}, nil
 }
 
-func NewServer(host string, port string, logger common.Logger, app *app.App) *GRPCServer {
-	return &GRPCServer{host: host, port: port, app: app, log: logger}
+func (s *GRPCServer) ListEventRange(
+	ctx context.Context, request *gen.ListEventRangeRequest,
+) (*gen.ListEventRangeResponse, error) {
+	page, err := s.app.ListEventRange(
+		ctx, request.OwnerId, request.From.AsTime(), request.To.AsTime(), int(request.PageSize), request.PageToken,
+	)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	result := &gen.ListEventRangeResponse{Events: make([]*gen.Event, 0, len(page.Events)), NextPageToken: page.NextToken}
+	for _, e := range page.Events {
+		result.Events = append(result.Events, newEventMessage(e))
+	}
+	return result, nil
 }
 
-func (s *GRPCServer) Start() error {
-	addr := net.JoinHostPort(s.host, s.port)
+func (s *GRPCServer) SearchEvents(
+	ctx context.Context, request *gen.SearchEventsRequest,
+) (*gen.SearchEventsResponse, error) {
+	query := models.SearchQuery{Text: request.Text, Owner: request.OwnerId, Limit: int(request.Limit)}
+	if request.From != nil {
+		query.From = request.From.AsTime()
+	}
+	if request.To != nil {
+		query.To = request.To.AsTime()
+	}
+	events, err := s.app.SearchEvents(ctx, query)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	result := &gen.SearchEventsResponse{Events: make([]*gen.Event, 0, len(events))}
+	for _, e := range events {
+		result.Events = append(result.Events, newEventMessage(e))
+	}
+	return result, nil
+}
+
+// ListTrash lists the deleted events of the owner
+func (s *GRPCServer) ListTrash(ctx context.Context, request *gen.ListTrashRequest) (*gen.ListTrashResponse, error) {
+	events, err := s.app.ListTrash(ctx, request.OwnerId)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	result := &gen.ListTrashResponse{Events: make([]*gen.Event, 0, len(events))}
+	for _, e := range events {
+		result.Events = append(result.Events, newEventMessage(e))
+	}
+	return result, nil
+}
+
+// RestoreEvent brings the event back from the trash, a non-zero version of the request must be its version
+func (s *GRPCServer) RestoreEvent(ctx context.Context, id *gen.EventId) (*gen.Event, error) {
+	e, err := s.app.RestoreEvent(ctx, id.GetId(), id.GetVersion())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return newEventMessage(e), nil
+}
+
+// PurgeEvent removes the event from the trash for good, a non-zero version of the request must be its version
+func (s *GRPCServer) PurgeEvent(ctx context.Context, id *gen.EventId) (*empty.Empty, error) {
+	if err := s.app.PurgeEvent(ctx, id.GetId(), id.GetVersion()); err != nil {
+		return nil, statusError(err)
+	}
+	return &empty.Empty{}, nil
+}
+
+// GetEventHistory lists the changes of the event oldest first
+func (s *GRPCServer) GetEventHistory(ctx context.Context, id *gen.EventId) (*gen.GetEventHistoryResponse, error) {
+	records, err := s.app.GetEventHistory(ctx, id.GetId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	result := &gen.GetEventHistoryResponse{Records: make([]*gen.AuditRecord, 0, len(records))}
+	for _, r := range records {
+		record := &gen.AuditRecord{
+			EventId:   r.EventID,
+			Actor:     r.Actor,
+			At:        timestamppb.New(r.At),
+			Operation: string(r.Op),
+		}
+		if r.Before != nil {
+			record.Before = newEventMessage(r.Before)
+		}
+		if r.After != nil {
+			record.After = newEventMessage(r.After)
+		}
+		result.Records = append(result.Records, record)
+	}
+	return result, nil
+}
+
+// NewServer makes a server, calls are not authenticated when the verifier is nil
+func NewServer(host string, port string, logger common.Logger, app *app.App, verifier *auth.Verifier) *GRPCServer {
+	return &GRPCServer{host: host, port: port, app: app, log: logger, verifier: verifier}
+}
+
+// authenticate puts the identity of the bearer token owner in the context
+func (s *GRPCServer) authenticate(ctx context.Context) (context.Context, error) {
+	token, err := grpc_auth.AuthFromMD(ctx, "bearer")
+	if err != nil {
+		return nil, err
+	}
+	identity, err := s.verifier.Verify(token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return auth.NewContext(ctx, identity), nil
+}
+
+func (s *GRPCServer) serverOptions() []grpc.ServerOption {
 	logrusEntry := logrus.NewEntry(logrus.StandardLogger())
+	unary := []grpc.UnaryServerInterceptor{
+		grpc_ctxtags.UnaryServerInterceptor(grpc_ctxtags.WithFieldExtractor(grpc_ctxtags.CodeGenRequestFieldExtractor)),
+		grpc_logrus.UnaryServerInterceptor(logrusEntry),
+	}
+	stream := []grpc.StreamServerInterceptor{
+		grpc_ctxtags.StreamServerInterceptor(grpc_ctxtags.WithFieldExtractor(grpc_ctxtags.CodeGenRequestFieldExtractor)),
+		grpc_logrus.StreamServerInterceptor(logrusEntry),
+	}
+	if s.verifier != nil {
+		unary = append(unary, grpc_auth.UnaryServerInterceptor(s.authenticate))
+		stream = append(stream, grpc_auth.StreamServerInterceptor(s.authenticate))
+	}
 
-	s.server = grpc.NewServer(
-		grpc_middleware.WithUnaryServerChain(
-			grpc_ctxtags.UnaryServerInterceptor(grpc_ctxtags.WithFieldExtractor(grpc_ctxtags.CodeGenRequestFieldExtractor)),
-			grpc_logrus.UnaryServerInterceptor(logrusEntry),
-		),
-		grpc_middleware.WithStreamServerChain(
-			grpc_ctxtags.StreamServerInterceptor(grpc_ctxtags.WithFieldExtractor(grpc_ctxtags.CodeGenRequestFieldExtractor)),
-			grpc_logrus.StreamServerInterceptor(logrusEntry),
-		),
-	)
+	return []grpc.ServerOption{
+		grpc_middleware.WithUnaryServerChain(unary...),
+		grpc_middleware.WithStreamServerChain(stream...),
+	}
+}
+
+func (s *GRPCServer) Start() error {
+	addr := net.JoinHostPort(s.host, s.port)
+	s.server = grpc.NewServer(s.serverOptions()...)
 	gen.RegisterCalendarServiceServer(s.server, s)
 
 	listen, err := net.Listen("tcp", addr)
@@ -1,7 +1,10 @@
 package serverhttp
 
 import (
+	"errors"
 	"net/http"
+
+	"github.com/VladNF/calendar/internal/models"
 )
 
 func (s *HTTPServer) NoEror(w http.ResponseWriter, r *http.Request) {
@@ -20,6 +23,49 @@ func (s *HTTPServer) NotFound(err error, w http.ResponseWriter, r *http.Request)
 	s.httpRespondWithError(err, w, r, "Not found", http.StatusNotFound)
 }
 
+func (s *HTTPServer) Forbidden(err error, w http.ResponseWriter, r *http.Request) {
+	s.httpRespondWithError(err, w, r, "Forbidden", http.StatusForbidden)
+}
+
+func (s *HTTPServer) Conflict(err error, w http.ResponseWriter, r *http.Request) {
+	s.httpRespondWithError(err, w, r, "Conflict", http.StatusConflict)
+}
+
+func (s *HTTPServer) PreconditionFailed(err error, w http.ResponseWriter, r *http.Request) {
+	s.httpRespondWithError(err, w, r, "Precondition failed", http.StatusPreconditionFailed)
+}
+
+func (s *HTTPServer) UnsupportedMediaType(err error, w http.ResponseWriter, r *http.Request) {
+	s.httpRespondWithError(err, w, r, "Unsupported media type", http.StatusUnsupportedMediaType)
+}
+
+// AppError responds with the status matching the kind of the app error, version conflicts fail
+// the precondition only when the request had one
+func (s *HTTPServer) AppError(err error, w http.ResponseWriter, r *http.Request) {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		s.NotFound(err, w, r)
+	case errors.Is(err, models.ErrValueError):
+		s.BadRequest(err, w, r)
+	case errors.Is(err, models.ErrForbidden):
+		s.Forbidden(err, w, r)
+	case errors.Is(err, models.ErrSlotBusy), errors.Is(err, models.ErrAlreadyExists):
+		s.Conflict(err, w, r)
+	case errors.Is(err, models.ErrConflict) && hasIfMatch(r):
+		s.PreconditionFailed(err, w, r)
+	case errors.Is(err, models.ErrConflict):
+		s.Conflict(err, w, r)
+	default:
+		s.InternalError(err, w, r)
+	}
+}
+
+// hasIfMatch tells whether the request requires a version of the event, any version does not
+func hasIfMatch(r *http.Request) bool {
+	value := r.Header.Get("If-Match")
+	return value != "" && value != "*"
+}
+
 func (s *HTTPServer) httpRespondWithError(err error, w http.ResponseWriter, _ *http.Request, logMsg string, status int) {
 	s.log.Errorf("%v - %v", logMsg, err)
 	w.WriteHeader(status)
@@ -14,70 +14,775 @@ import (
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
 
+	// (GET /calendar/events.ics)
+	ExportEvents(w http.ResponseWriter, r *http.Request, params ExportEventsParams)
+
+	// (POST /calendar/events.ics)
+	ImportEvents(w http.ResponseWriter, r *http.Request, params ImportEventsParams)
+
 	// (GET /calendar/events/)
 	ListEvents(w http.ResponseWriter, r *http.Request, params ListEventsParams)
 
-	// (POST /calendar/events/)
-	CreateEvent(w http.ResponseWriter, r *http.Request)
+	// (POST /calendar/events/)
+	CreateEvent(w http.ResponseWriter, r *http.Request, params CreateEventParams)
+
+	// (DELETE /calendar/events/{id})
+	DeleteEvent(w http.ResponseWriter, r *http.Request, id string, params DeleteEventParams)
+
+	// (GET /calendar/events/{id})
+	GetEvent(w http.ResponseWriter, r *http.Request, id string)
+
+	// (PATCH /calendar/events/{id})
+	PatchEvent(w http.ResponseWriter, r *http.Request, id string, params PatchEventParams)
+
+	// (PUT /calendar/events/{id})
+	PutEvent(w http.ResponseWriter, r *http.Request, id string, params PutEventParams)
+
+	// (POST /calendar/events/{id}/attendees)
+	AddAttendee(w http.ResponseWriter, r *http.Request, id string)
+
+	// (DELETE /calendar/events/{id}/attendees/{user_id})
+	RemoveAttendee(w http.ResponseWriter, r *http.Request, id string, userId string)
+
+	// (PUT /calendar/events/{id}/attendees/{user_id})
+	RespondToEvent(w http.ResponseWriter, r *http.Request, id string, userId string)
+
+	// (GET /calendar/events/{id}/history)
+	GetEventHistory(w http.ResponseWriter, r *http.Request, id string)
+
+	// (GET /calendar/freebusy)
+	GetFreeBusy(w http.ResponseWriter, r *http.Request, params GetFreeBusyParams)
+
+	// (GET /calendar/range)
+	ListEventRange(w http.ResponseWriter, r *http.Request, params ListEventRangeParams)
+
+	// (GET /calendar/search)
+	SearchEvents(w http.ResponseWriter, r *http.Request, params SearchEventsParams)
+
+	// (GET /calendar/slots)
+	FindSlots(w http.ResponseWriter, r *http.Request, params FindSlotsParams)
+
+	// (GET /calendar/trash)
+	ListTrash(w http.ResponseWriter, r *http.Request, params ListTrashParams)
+
+	// (DELETE /calendar/trash/{id})
+	PurgeEvent(w http.ResponseWriter, r *http.Request, id string, params PurgeEventParams)
+
+	// (POST /calendar/trash/{id}/restore)
+	RestoreEvent(w http.ResponseWriter, r *http.Request, id string, params RestoreEventParams)
+}
+
+// ServerInterfaceWrapper converts contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler            ServerInterface
+	HandlerMiddlewares []MiddlewareFunc
+	ErrorHandlerFunc   func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+type MiddlewareFunc func(http.HandlerFunc) http.HandlerFunc
+
+// ExportEvents operation middleware
+func (siw *ServerInterfaceWrapper) ExportEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ExportEventsParams
+
+	// ------------- Optional query parameter "agenda" -------------
+	if paramValue := r.URL.Query().Get("agenda"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "agenda", r.URL.Query(), &params.Agenda)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "agenda", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "start_from" -------------
+	if paramValue := r.URL.Query().Get("start_from"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "start_from", r.URL.Query(), &params.StartFrom)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "start_from", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "owner_id" -------------
+	if paramValue := r.URL.Query().Get("owner_id"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "owner_id", r.URL.Query(), &params.OwnerId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "owner_id", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "timezone" -------------
+	if paramValue := r.URL.Query().Get("timezone"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "timezone", r.URL.Query(), &params.Timezone)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "timezone", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "week_start" -------------
+	if paramValue := r.URL.Query().Get("week_start"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "week_start", r.URL.Query(), &params.WeekStart)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "week_start", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "week" -------------
+	if paramValue := r.URL.Query().Get("week"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "week", r.URL.Query(), &params.Week)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "week", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ExportEvents(w, r, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// ImportEvents operation middleware
+func (siw *ServerInterfaceWrapper) ImportEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ImportEventsParams
+
+	// ------------- Optional query parameter "owner_id" -------------
+	if paramValue := r.URL.Query().Get("owner_id"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "owner_id", r.URL.Query(), &params.OwnerId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "owner_id", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ImportEvents(w, r, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// ListEvents operation middleware
+func (siw *ServerInterfaceWrapper) ListEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListEventsParams
+
+	// ------------- Required query parameter "agenda" -------------
+	if paramValue := r.URL.Query().Get("agenda"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "agenda"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "agenda", r.URL.Query(), &params.Agenda)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "agenda", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "start_from" -------------
+	if paramValue := r.URL.Query().Get("start_from"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "start_from", r.URL.Query(), &params.StartFrom)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "start_from", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "owner_id" -------------
+	if paramValue := r.URL.Query().Get("owner_id"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "owner_id", r.URL.Query(), &params.OwnerId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "owner_id", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "timezone" -------------
+	if paramValue := r.URL.Query().Get("timezone"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "timezone", r.URL.Query(), &params.Timezone)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "timezone", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "week_start" -------------
+	if paramValue := r.URL.Query().Get("week_start"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "week_start", r.URL.Query(), &params.WeekStart)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "week_start", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "week" -------------
+	if paramValue := r.URL.Query().Get("week"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "week", r.URL.Query(), &params.Week)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "week", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListEvents(w, r, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// CreateEvent operation middleware
+func (siw *ServerInterfaceWrapper) CreateEvent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params CreateEventParams
+
+	// ------------- Optional query parameter "allow_overlap" -------------
+	if paramValue := r.URL.Query().Get("allow_overlap"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "allow_overlap", r.URL.Query(), &params.AllowOverlap)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "allow_overlap", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.CreateEvent(w, r, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// DeleteEvent operation middleware
+func (siw *ServerInterfaceWrapper) DeleteEvent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params DeleteEventParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "If-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Match")]; found {
+		var IfMatch IfMatch
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "If-Match", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-Match", runtime.ParamLocationHeader, valueList[0], &IfMatch)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "If-Match", Err: err})
+			return
+		}
+
+		params.IfMatch = &IfMatch
+
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteEvent(w, r, id, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetEvent operation middleware
+func (siw *ServerInterfaceWrapper) GetEvent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetEvent(w, r, id)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// PatchEvent operation middleware
+func (siw *ServerInterfaceWrapper) PatchEvent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PatchEventParams
+
+	// ------------- Optional query parameter "allow_overlap" -------------
+	if paramValue := r.URL.Query().Get("allow_overlap"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "allow_overlap", r.URL.Query(), &params.AllowOverlap)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "allow_overlap", Err: err})
+		return
+	}
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "If-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Match")]; found {
+		var IfMatch IfMatch
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "If-Match", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-Match", runtime.ParamLocationHeader, valueList[0], &IfMatch)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "If-Match", Err: err})
+			return
+		}
+
+		params.IfMatch = &IfMatch
+
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PatchEvent(w, r, id, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// PutEvent operation middleware
+func (siw *ServerInterfaceWrapper) PutEvent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PutEventParams
+
+	// ------------- Optional query parameter "allow_overlap" -------------
+	if paramValue := r.URL.Query().Get("allow_overlap"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "allow_overlap", r.URL.Query(), &params.AllowOverlap)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "allow_overlap", Err: err})
+		return
+	}
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "If-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Match")]; found {
+		var IfMatch IfMatch
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "If-Match", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-Match", runtime.ParamLocationHeader, valueList[0], &IfMatch)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "If-Match", Err: err})
+			return
+		}
+
+		params.IfMatch = &IfMatch
+
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PutEvent(w, r, id, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// AddAttendee operation middleware
+func (siw *ServerInterfaceWrapper) AddAttendee(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.AddAttendee(w, r, id)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// RemoveAttendee operation middleware
+func (siw *ServerInterfaceWrapper) RemoveAttendee(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	// ------------- Path parameter "user_id" -------------
+	var userId string
+
+	err = runtime.BindStyledParameter("simple", false, "user_id", chi.URLParam(r, "user_id"), &userId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "user_id", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.RemoveAttendee(w, r, id, userId)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// RespondToEvent operation middleware
+func (siw *ServerInterfaceWrapper) RespondToEvent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	// ------------- Path parameter "user_id" -------------
+	var userId string
+
+	err = runtime.BindStyledParameter("simple", false, "user_id", chi.URLParam(r, "user_id"), &userId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "user_id", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.RespondToEvent(w, r, id, userId)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetEventHistory operation middleware
+func (siw *ServerInterfaceWrapper) GetEventHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetEventHistory(w, r, id)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetFreeBusy operation middleware
+func (siw *ServerInterfaceWrapper) GetFreeBusy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetFreeBusyParams
+
+	// ------------- Required query parameter "owner_id" -------------
+	if paramValue := r.URL.Query().Get("owner_id"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "owner_id"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "owner_id", r.URL.Query(), &params.OwnerId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "owner_id", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "from" -------------
+	if paramValue := r.URL.Query().Get("from"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "from"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "from", r.URL.Query(), &params.From)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "from", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "to" -------------
+	if paramValue := r.URL.Query().Get("to"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "to"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "to", r.URL.Query(), &params.To)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "to", Err: err})
+		return
+	}
 
-	// (DELETE /calendar/events/{id})
-	DeleteEvent(w http.ResponseWriter, r *http.Request, id string)
+	// ------------- Optional query parameter "min_free" -------------
+	if paramValue := r.URL.Query().Get("min_free"); paramValue != "" {
 
-	// (GET /calendar/events/{id})
-	GetEvent(w http.ResponseWriter, r *http.Request, id string)
+	}
 
-	// (PUT /calendar/events/{id})
-	PutEvent(w http.ResponseWriter, r *http.Request, id string)
-}
+	err = runtime.BindQueryParameter("form", true, false, "min_free", r.URL.Query(), &params.MinFree)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "min_free", Err: err})
+		return
+	}
 
-// ServerInterfaceWrapper converts contexts to parameters.
-type ServerInterfaceWrapper struct {
-	Handler            ServerInterface
-	HandlerMiddlewares []MiddlewareFunc
-	ErrorHandlerFunc   func(w http.ResponseWriter, r *http.Request, err error)
-}
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetFreeBusy(w, r, params)
+	}
 
-type MiddlewareFunc func(http.HandlerFunc) http.HandlerFunc
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
 
-// ListEvents operation middleware
-func (siw *ServerInterfaceWrapper) ListEvents(w http.ResponseWriter, r *http.Request) {
+	handler(w, r.WithContext(ctx))
+}
+
+// ListEventRange operation middleware
+func (siw *ServerInterfaceWrapper) ListEventRange(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	var err error
 
 	// Parameter object where we will unmarshal all parameters from the context
-	var params ListEventsParams
+	var params ListEventRangeParams
 
-	// ------------- Required query parameter "agenda" -------------
-	if paramValue := r.URL.Query().Get("agenda"); paramValue != "" {
+	// ------------- Required query parameter "from" -------------
+	if paramValue := r.URL.Query().Get("from"); paramValue != "" {
 
 	} else {
-		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "agenda"})
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "from"})
 		return
 	}
 
-	err = runtime.BindQueryParameter("form", true, true, "agenda", r.URL.Query(), &params.Agenda)
+	err = runtime.BindQueryParameter("form", true, true, "from", r.URL.Query(), &params.From)
 	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "agenda", Err: err})
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "from", Err: err})
 		return
 	}
 
-	// ------------- Required query parameter "start_from" -------------
-	if paramValue := r.URL.Query().Get("start_from"); paramValue != "" {
+	// ------------- Required query parameter "to" -------------
+	if paramValue := r.URL.Query().Get("to"); paramValue != "" {
 
 	} else {
-		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "start_from"})
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "to"})
 		return
 	}
 
-	err = runtime.BindQueryParameter("form", true, true, "start_from", r.URL.Query(), &params.StartFrom)
+	err = runtime.BindQueryParameter("form", true, true, "to", r.URL.Query(), &params.To)
 	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "start_from", Err: err})
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "to", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "owner_id" -------------
+	if paramValue := r.URL.Query().Get("owner_id"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "owner_id", r.URL.Query(), &params.OwnerId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "owner_id", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "page_size" -------------
+	if paramValue := r.URL.Query().Get("page_size"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "page_size", r.URL.Query(), &params.PageSize)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "page_size", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "page_token" -------------
+	if paramValue := r.URL.Query().Get("page_token"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "page_token", r.URL.Query(), &params.PageToken)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "page_token", Err: err})
 		return
 	}
 
 	var handler = func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.ListEvents(w, r, params)
+		siw.Handler.ListEventRange(w, r, params)
 	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -87,12 +792,75 @@ func (siw *ServerInterfaceWrapper) ListEvents(w http.ResponseWriter, r *http.Req
 	handler(w, r.WithContext(ctx))
 }
 
-// CreateEvent operation middleware
-func (siw *ServerInterfaceWrapper) CreateEvent(w http.ResponseWriter, r *http.Request) {
+// SearchEvents operation middleware
+func (siw *ServerInterfaceWrapper) SearchEvents(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params SearchEventsParams
+
+	// ------------- Required query parameter "q" -------------
+	if paramValue := r.URL.Query().Get("q"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "q"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "q", r.URL.Query(), &params.Q)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "q", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "owner_id" -------------
+	if paramValue := r.URL.Query().Get("owner_id"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "owner_id", r.URL.Query(), &params.OwnerId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "owner_id", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "from" -------------
+	if paramValue := r.URL.Query().Get("from"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "from", r.URL.Query(), &params.From)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "from", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "to" -------------
+	if paramValue := r.URL.Query().Get("to"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "to", r.URL.Query(), &params.To)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "to", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+	if paramValue := r.URL.Query().Get("limit"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
 	var handler = func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.CreateEvent(w, r)
+		siw.Handler.SearchEvents(w, r, params)
 	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -102,23 +870,128 @@ func (siw *ServerInterfaceWrapper) CreateEvent(w http.ResponseWriter, r *http.Re
 	handler(w, r.WithContext(ctx))
 }
 
-// DeleteEvent operation middleware
-func (siw *ServerInterfaceWrapper) DeleteEvent(w http.ResponseWriter, r *http.Request) {
+// FindSlots operation middleware
+func (siw *ServerInterfaceWrapper) FindSlots(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	var err error
 
-	// ------------- Path parameter "id" -------------
-	var id string
+	// Parameter object where we will unmarshal all parameters from the context
+	var params FindSlotsParams
 
-	err = runtime.BindStyledParameter("simple", false, "id", chi.URLParam(r, "id"), &id)
+	// ------------- Required query parameter "attendee" -------------
+	if paramValue := r.URL.Query().Get("attendee"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "attendee"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "attendee", r.URL.Query(), &params.Attendee)
 	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "attendee", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "duration" -------------
+	if paramValue := r.URL.Query().Get("duration"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "duration"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "duration", r.URL.Query(), &params.Duration)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "duration", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "from" -------------
+	if paramValue := r.URL.Query().Get("from"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "from"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "from", r.URL.Query(), &params.From)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "from", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "to" -------------
+	if paramValue := r.URL.Query().Get("to"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "to"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "to", r.URL.Query(), &params.To)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "to", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "work_start" -------------
+	if paramValue := r.URL.Query().Get("work_start"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "work_start", r.URL.Query(), &params.WorkStart)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "work_start", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "work_end" -------------
+	if paramValue := r.URL.Query().Get("work_end"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "work_end", r.URL.Query(), &params.WorkEnd)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "work_end", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "work_day" -------------
+	if paramValue := r.URL.Query().Get("work_day"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "work_day", r.URL.Query(), &params.WorkDay)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "work_day", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "timezone" -------------
+	if paramValue := r.URL.Query().Get("timezone"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "timezone", r.URL.Query(), &params.Timezone)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "timezone", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+	if paramValue := r.URL.Query().Get("limit"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
 		return
 	}
 
 	var handler = func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.DeleteEvent(w, r, id)
+		siw.Handler.FindSlots(w, r, params)
 	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -128,8 +1001,39 @@ func (siw *ServerInterfaceWrapper) DeleteEvent(w http.ResponseWriter, r *http.Re
 	handler(w, r.WithContext(ctx))
 }
 
-// GetEvent operation middleware
-func (siw *ServerInterfaceWrapper) GetEvent(w http.ResponseWriter, r *http.Request) {
+// ListTrash operation middleware
+func (siw *ServerInterfaceWrapper) ListTrash(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListTrashParams
+
+	// ------------- Optional query parameter "owner_id" -------------
+	if paramValue := r.URL.Query().Get("owner_id"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "owner_id", r.URL.Query(), &params.OwnerId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "owner_id", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListTrash(w, r, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// PurgeEvent operation middleware
+func (siw *ServerInterfaceWrapper) PurgeEvent(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	var err error
@@ -143,8 +1047,32 @@ func (siw *ServerInterfaceWrapper) GetEvent(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PurgeEventParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "If-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Match")]; found {
+		var IfMatch IfMatch
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "If-Match", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-Match", runtime.ParamLocationHeader, valueList[0], &IfMatch)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "If-Match", Err: err})
+			return
+		}
+
+		params.IfMatch = &IfMatch
+
+	}
+
 	var handler = func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.GetEvent(w, r, id)
+		siw.Handler.PurgeEvent(w, r, id, params)
 	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -154,8 +1082,8 @@ func (siw *ServerInterfaceWrapper) GetEvent(w http.ResponseWriter, r *http.Reque
 	handler(w, r.WithContext(ctx))
 }
 
-// PutEvent operation middleware
-func (siw *ServerInterfaceWrapper) PutEvent(w http.ResponseWriter, r *http.Request) {
+// RestoreEvent operation middleware
+func (siw *ServerInterfaceWrapper) RestoreEvent(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	var err error
@@ -169,8 +1097,32 @@ func (siw *ServerInterfaceWrapper) PutEvent(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params RestoreEventParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "If-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Match")]; found {
+		var IfMatch IfMatch
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "If-Match", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-Match", runtime.ParamLocationHeader, valueList[0], &IfMatch)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "If-Match", Err: err})
+			return
+		}
+
+		params.IfMatch = &IfMatch
+
+	}
+
 	var handler = func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.PutEvent(w, r, id)
+		siw.Handler.RestoreEvent(w, r, id, params)
 	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -293,6 +1245,12 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 		ErrorHandlerFunc:   options.ErrorHandlerFunc,
 	}
 
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/calendar/events.ics", wrapper.ExportEvents)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/calendar/events.ics", wrapper.ImportEvents)
+	})
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/calendar/events/", wrapper.ListEvents)
 	})
@@ -305,9 +1263,45 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/calendar/events/{id}", wrapper.GetEvent)
 	})
+	r.Group(func(r chi.Router) {
+		r.Patch(options.BaseURL+"/calendar/events/{id}", wrapper.PatchEvent)
+	})
 	r.Group(func(r chi.Router) {
 		r.Put(options.BaseURL+"/calendar/events/{id}", wrapper.PutEvent)
 	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/calendar/events/{id}/attendees", wrapper.AddAttendee)
+	})
+	r.Group(func(r chi.Router) {
+		r.Delete(options.BaseURL+"/calendar/events/{id}/attendees/{user_id}", wrapper.RemoveAttendee)
+	})
+	r.Group(func(r chi.Router) {
+		r.Put(options.BaseURL+"/calendar/events/{id}/attendees/{user_id}", wrapper.RespondToEvent)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/calendar/events/{id}/history", wrapper.GetEventHistory)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/calendar/freebusy", wrapper.GetFreeBusy)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/calendar/range", wrapper.ListEventRange)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/calendar/search", wrapper.SearchEvents)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/calendar/slots", wrapper.FindSlots)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/calendar/trash", wrapper.ListTrash)
+	})
+	r.Group(func(r chi.Router) {
+		r.Delete(options.BaseURL+"/calendar/trash/{id}", wrapper.PurgeEvent)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/calendar/trash/{id}/restore", wrapper.RestoreEvent)
+	})
 
 	return r
 }
@@ -7,22 +7,201 @@ import (
 	"time"
 )
 
+// Defines values for AuditRecordOperation.
+const (
+	AuditRecordOperationCreate AuditRecordOperation = "create"
+
+	AuditRecordOperationDelete AuditRecordOperation = "delete"
+
+	AuditRecordOperationPurge AuditRecordOperation = "purge"
+
+	AuditRecordOperationRestore AuditRecordOperation = "restore"
+
+	AuditRecordOperationUpdate AuditRecordOperation = "update"
+)
+
+// Defines values for ResponseStatus.
+const (
+	ResponseStatusAccepted ResponseStatus = "accepted"
+
+	ResponseStatusDeclined ResponseStatus = "declined"
+
+	ResponseStatusNeedsAction ResponseStatus = "needs-action"
+
+	ResponseStatusTentative ResponseStatus = "tentative"
+)
+
+// Attendee defines model for Attendee.
+type Attendee struct {
+	Status *ResponseStatus `json:"status,omitempty"`
+	UserId string          `json:"user_id"`
+}
+
+// AuditRecord defines model for AuditRecord.
+type AuditRecord struct {
+	// the authenticated caller who made the change, empty when the change was not authenticated
+	Actor     string               `json:"actor"`
+	After     *Event               `json:"after,omitempty"`
+	At        time.Time            `json:"at"`
+	Before    *Event               `json:"before,omitempty"`
+	EventId   string               `json:"event_id"`
+	Operation AuditRecordOperation `json:"operation"`
+}
+
+// AuditRecordOperation defines model for AuditRecord.Operation.
+type AuditRecordOperation string
+
 // Event defines model for Event.
 type Event struct {
 	// time interval in seconds before the start time
-	AlertBefore int       `json:"alert_before"`
-	EndsAt      time.Time `json:"ends_at"`
-	Id          string    `json:"id"`
-	Notes       string    `json:"notes"`
-	OwnerId     string    `json:"owner_id"`
-	StartsAt    time.Time `json:"starts_at"`
-	Title       string    `json:"title"`
+	AlertBefore int `json:"alert_before"`
+
+	// the event spans whole dates regardless of timezone, only dates of starts_at and ends_at are used and ends_at is the day after the last date of the event
+	AllDay *bool `json:"all_day,omitempty"`
+
+	// invited users, they are changed by the attendee operations only
+	Attendees *[]Attendee `json:"attendees,omitempty"`
+
+	// the time the event was moved into the trash, it is only set on events of the trash
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	EndsAt    time.Time  `json:"ends_at"`
+
+	// start times of the excluded occurrences of a recurring event
+	Exdates *[]time.Time `json:"exdates,omitempty"`
+	Id      string       `json:"id"`
+	Notes   string       `json:"notes"`
+
+	// start of the occurrence expanded from a recurring event with the given id
+	OccurrenceStart *time.Time `json:"occurrence_start,omitempty"`
+	OwnerId         string     `json:"owner_id"`
+
+	// RFC 5545 recurrence rule, e.g. FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10
+	Recurrence *string   `json:"recurrence,omitempty"`
+	StartsAt   time.Time `json:"starts_at"`
+
+	// IANA timezone the wall clock of a recurring event is kept in, e.g. Europe/Berlin, occurrences are expanded in UTC without it
+	Timezone *string `json:"timezone,omitempty"`
+	Title    string  `json:"title"`
+
+	// the time the event was stored last
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+
+	// the number of times the event was stored, it is the ETag of the event
+	Version *int64 `json:"version,omitempty"`
+}
+
+// EventPage defines model for EventPage.
+type EventPage struct {
+	Events []Event `json:"events"`
+
+	// token of the next page, it is missing on the last page
+	NextPageToken *string `json:"next_page_token,omitempty"`
+}
+
+// fields of Event to change, title, starts_at, ends_at and owner_id may not be removed
+type EventPatch struct {
+	AlertBefore *int         `json:"alert_before"`
+	AllDay      *bool        `json:"all_day"`
+	EndsAt      *time.Time   `json:"ends_at,omitempty"`
+	Exdates     *[]time.Time `json:"exdates"`
+	Notes       *string      `json:"notes"`
+	OwnerId     *string      `json:"owner_id,omitempty"`
+	Recurrence  *string      `json:"recurrence"`
+	StartsAt    *time.Time   `json:"starts_at,omitempty"`
+	Timezone    *string      `json:"timezone"`
+	Title       *string      `json:"title,omitempty"`
+}
+
+// FreeBusy defines model for FreeBusy.
+type FreeBusy struct {
+	Busy []OwnerBusy `json:"busy"`
+	Free []Interval  `json:"free"`
+}
+
+// ImportResult defines model for ImportResult.
+type ImportResult struct {
+	// the reason the entry was rejected
+	Error *string `json:"error,omitempty"`
+
+	// id of the stored event
+	Id       *string `json:"id,omitempty"`
+	Imported bool    `json:"imported"`
+
+	// line of the feed the entry begins at
+	Line int    `json:"line"`
+	Uid  string `json:"uid"`
+}
+
+// Interval defines model for Interval.
+type Interval struct {
+	End   time.Time `json:"end"`
+	Start time.Time `json:"start"`
+}
+
+// OwnerBusy defines model for OwnerBusy.
+type OwnerBusy struct {
+	Intervals []Interval `json:"intervals"`
+	OwnerId   string     `json:"owner_id"`
+}
+
+// ResponseStatus defines model for ResponseStatus.
+type ResponseStatus string
+
+// Rsvp defines model for Rsvp.
+type Rsvp struct {
+	Status ResponseStatus `json:"status"`
+}
+
+// AllowOverlap defines model for AllowOverlap.
+type AllowOverlap bool
+
+// IfMatch defines model for IfMatch.
+type IfMatch string
+
+// ExportEventsParams defines parameters for ExportEvents.
+type ExportEventsParams struct {
+	Agenda *ExportEventsParamsAgenda `json:"agenda,omitempty"`
+
+	// required along with the agenda unless the week is given
+	StartFrom *time.Time `json:"start_from,omitempty"`
+	OwnerId   *string    `json:"owner_id,omitempty"`
+
+	// IANA timezone the agenda days are taken in, the offset of start_from by default
+	Timezone *string `json:"timezone,omitempty"`
+
+	// first day of the weekly agenda as a two-letter code like MO, the deployment default otherwise
+	WeekStart *string `json:"week_start,omitempty"`
+
+	// ISO 8601 week like 2026-W42 of the weekly agenda instead of start_from, the week starts on Monday
+	Week *string `json:"week,omitempty"`
+}
+
+// ExportEventsParamsAgenda defines parameters for ExportEvents.
+type ExportEventsParamsAgenda string
+
+// ImportEventsParams defines parameters for ImportEvents.
+type ImportEventsParams struct {
+	OwnerId *string `json:"owner_id,omitempty"`
 }
 
 // ListEventsParams defines parameters for ListEvents.
 type ListEventsParams struct {
-	Agenda    ListEventsParamsAgenda `json:"agenda"`
-	StartFrom time.Time              `json:"start_from"`
+	Agenda ListEventsParamsAgenda `json:"agenda"`
+
+	// required unless the week is given
+	StartFrom *time.Time `json:"start_from,omitempty"`
+
+	// list events of the given owner only
+	OwnerId *string `json:"owner_id,omitempty"`
+
+	// IANA timezone the agenda days are taken in, the offset of start_from by default
+	Timezone *string `json:"timezone,omitempty"`
+
+	// first day of the weekly agenda as a two-letter code like MO, the deployment default otherwise
+	WeekStart *string `json:"week_start,omitempty"`
+
+	// ISO 8601 week like 2026-W42 of the weekly agenda instead of start_from, the week starts on Monday
+	Week *string `json:"week,omitempty"`
 }
 
 // ListEventsParamsAgenda defines parameters for ListEvents.
@@ -31,11 +210,136 @@ type ListEventsParamsAgenda string
 // CreateEventJSONBody defines parameters for CreateEvent.
 type CreateEventJSONBody Event
 
+// CreateEventParams defines parameters for CreateEvent.
+type CreateEventParams struct {
+	// store the event even if the owner has other events in the time slot, e.g. a tentative one
+	AllowOverlap *AllowOverlap `json:"allow_overlap,omitempty"`
+}
+
+// DeleteEventParams defines parameters for DeleteEvent.
+type DeleteEventParams struct {
+	// the ETag of the event the change is based on, the change is rejected when the event has been changed since, * matches any version
+	IfMatch *IfMatch `json:"If-Match,omitempty"`
+}
+
+// PatchEventParams defines parameters for PatchEvent.
+type PatchEventParams struct {
+	// store the event even if the owner has other events in the time slot, e.g. a tentative one
+	AllowOverlap *AllowOverlap `json:"allow_overlap,omitempty"`
+
+	// the ETag of the event the change is based on, the change is rejected when the event has been changed since, * matches any version
+	IfMatch *IfMatch `json:"If-Match,omitempty"`
+}
+
 // PutEventJSONBody defines parameters for PutEvent.
 type PutEventJSONBody Event
 
+// PutEventParams defines parameters for PutEvent.
+type PutEventParams struct {
+	// store the event even if the owner has other events in the time slot, e.g. a tentative one
+	AllowOverlap *AllowOverlap `json:"allow_overlap,omitempty"`
+
+	// the ETag of the event the change is based on, the change is rejected when the event has been changed since, * matches any version
+	IfMatch *IfMatch `json:"If-Match,omitempty"`
+}
+
+// AddAttendeeJSONBody defines parameters for AddAttendee.
+type AddAttendeeJSONBody Attendee
+
+// RespondToEventJSONBody defines parameters for RespondToEvent.
+type RespondToEventJSONBody Rsvp
+
+// GetFreeBusyParams defines parameters for GetFreeBusy.
+type GetFreeBusyParams struct {
+	OwnerId []string  `json:"owner_id"`
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+
+	// minimal duration of a free interval in seconds
+	MinFree *int `json:"min_free,omitempty"`
+}
+
+// ListEventRangeParams defines parameters for ListEventRange.
+type ListEventRangeParams struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+
+	// list events of the given owner only
+	OwnerId *string `json:"owner_id,omitempty"`
+
+	// maximal number of events on the page, 100 by default and at most 1000
+	PageSize  *int    `json:"page_size,omitempty"`
+	PageToken *string `json:"page_token,omitempty"`
+}
+
+// SearchEventsParams defines parameters for SearchEvents.
+type SearchEventsParams struct {
+	// search text
+	Q string `json:"q"`
+
+	// search events of the given owner only
+	OwnerId *string `json:"owner_id,omitempty"`
+
+	// start of the date range, required along with its end
+	From *time.Time `json:"from,omitempty"`
+
+	// end of the date range, required along with its start
+	To *time.Time `json:"to,omitempty"`
+
+	// maximal number of events, 50 by default and at most 500
+	Limit *int `json:"limit,omitempty"`
+}
+
+// FindSlotsParams defines parameters for FindSlots.
+type FindSlotsParams struct {
+	Attendee []string `json:"attendee"`
+
+	// duration of a slot in seconds
+	Duration int       `json:"duration"`
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+
+	// start of the working hours like 09:00, whole days are used without working hours
+	WorkStart *string `json:"work_start,omitempty"`
+
+	// end of the working hours like 18:00
+	WorkEnd *string `json:"work_end,omitempty"`
+
+	// working days as two-letter codes like MO, any day is used without them
+	WorkDay *[]string `json:"work_day,omitempty"`
+
+	// IANA timezone of the working hours, UTC by default
+	Timezone *string `json:"timezone,omitempty"`
+
+	// maximal number of slots, 5 by default and at most 100
+	Limit *int `json:"limit,omitempty"`
+}
+
+// ListTrashParams defines parameters for ListTrash.
+type ListTrashParams struct {
+	OwnerId *string `json:"owner_id,omitempty"`
+}
+
+// PurgeEventParams defines parameters for PurgeEvent.
+type PurgeEventParams struct {
+	// the ETag of the event the change is based on, the change is rejected when the event has been changed since, * matches any version
+	IfMatch *IfMatch `json:"If-Match,omitempty"`
+}
+
+// RestoreEventParams defines parameters for RestoreEvent.
+type RestoreEventParams struct {
+	// the ETag of the event the change is based on, the change is rejected when the event has been changed since, * matches any version
+	IfMatch *IfMatch `json:"If-Match,omitempty"`
+}
+
 // CreateEventJSONRequestBody defines body for CreateEvent for application/json ContentType.
 type CreateEventJSONRequestBody CreateEventJSONBody
 
 // PutEventJSONRequestBody defines body for PutEvent for application/json ContentType.
 type PutEventJSONRequestBody PutEventJSONBody
+
+// AddAttendeeJSONRequestBody defines body for AddAttendee for application/json ContentType.
+type AddAttendeeJSONRequestBody AddAttendeeJSONBody
+
+// RespondToEventJSONRequestBody defines body for RespondToEvent for application/json ContentType.
+type RespondToEventJSONRequestBody RespondToEventJSONBody
@@ -1,16 +1,22 @@
 package serverhttp
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/VladNF/calendar/internal/app"
+	"github.com/VladNF/calendar/internal/auth"
 	"github.com/VladNF/calendar/internal/common"
+	"github.com/VladNF/calendar/internal/models"
 	"github.com/VladNF/calendar/internal/server/http/gen"
 	"github.com/VladNF/calendar/internal/storage"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/stretchr/testify/require"
 )
 
@@ -36,7 +42,7 @@ func TestHttpServer(t *testing.T) {
 	startDate := time.Date(2021, 1, 1, 8, 0, 0, 0, time.UTC)
 	startTime := startDate.Add(8 * time.Hour)
 	t.Run("Get Event", func(t *testing.T) {
-		event, err := s.app.CreateEvent(ctx, "", "today event", startTime, startTime.Add(time.Hour), "test")
+		event, err := s.app.CreateEvent(ctx, "", "today event", startTime, startTime.Add(time.Hour), "test", false)
 		require.NoError(t, err)
 
 		expected := gen.Event{
@@ -59,10 +65,11 @@ func TestHttpServer(t *testing.T) {
 	})
 
 	t.Run("Delete Event", func(t *testing.T) {
-		event, err := s.app.CreateEvent(ctx, "", "today event", startTime, startTime.Add(time.Hour), "test")
+		startTime := startTime.Add(time.Hour)
+		event, err := s.app.CreateEvent(ctx, "", "today event", startTime, startTime.Add(time.Hour), "test", false)
 		require.NoError(t, err)
 
-		rDel, err := tc.DeleteEventWithResponse(ctx, event.ID)
+		rDel, err := tc.DeleteEventWithResponse(ctx, event.ID, &gen.DeleteEventParams{})
 		require.NoError(t, err)
 		require.Equal(t, rDel.StatusCode(), http.StatusOK)
 
@@ -72,7 +79,8 @@ func TestHttpServer(t *testing.T) {
 	})
 
 	t.Run("Update Event", func(t *testing.T) {
-		event, err := s.app.CreateEvent(ctx, "", "today event", startTime, startTime.Add(time.Hour), "test")
+		startTime := startTime.Add(2 * time.Hour)
+		event, err := s.app.CreateEvent(ctx, "", "today event", startTime, startTime.Add(time.Hour), "test", false)
 		require.NoError(t, err)
 
 		expected := gen.Event{
@@ -84,7 +92,7 @@ func TestHttpServer(t *testing.T) {
 			StartsAt:    event.StartsAt,
 			Title:       event.Title,
 		}
-		r, err := tc.PutEventWithResponse(ctx, event.ID, gen.PutEventJSONRequestBody(expected))
+		r, err := tc.PutEventWithResponse(ctx, event.ID, &gen.PutEventParams{}, gen.PutEventJSONRequestBody(expected))
 		require.NoError(t, err)
 		require.Equal(t, r.StatusCode(), http.StatusOK)
 		eventsIdentical(t, *r.JSON200, expected)
@@ -95,7 +103,201 @@ func TestHttpServer(t *testing.T) {
 		eventsIdentical(t, *rGet.JSON200, expected)
 	})
 
+	t.Run("Event Versions", func(t *testing.T) {
+		startTime := startTime.AddDate(0, 4, 0)
+		event, err := s.app.CreateEvent(ctx, "", "versioned event", startTime, startTime.Add(time.Hour), "test", false)
+		require.NoError(t, err)
+
+		rGet, err := tc.GetEventWithResponse(ctx, event.ID)
+		require.NoError(t, err)
+		require.Equal(t, `"1"`, rGet.HTTPResponse.Header.Get("ETag"))
+		require.EqualValues(t, 1, *rGet.JSON200.Version)
+
+		dto := gen.PutEventJSONRequestBody(*rGet.JSON200)
+		dto.Notes = "first edit"
+		ifMatch := gen.IfMatch(`"1"`)
+		rPut, err := tc.PutEventWithResponse(ctx, event.ID, &gen.PutEventParams{IfMatch: &ifMatch}, dto)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, rPut.StatusCode())
+		require.Equal(t, `"2"`, rPut.HTTPResponse.Header.Get("ETag"))
+
+		dto.Notes = "stale edit"
+		rPut, err = tc.PutEventWithResponse(ctx, event.ID, &gen.PutEventParams{IfMatch: &ifMatch}, dto)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusPreconditionFailed, rPut.StatusCode())
+		rDel, err := tc.DeleteEventWithResponse(ctx, event.ID, &gen.DeleteEventParams{IfMatch: &ifMatch})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusPreconditionFailed, rDel.StatusCode())
+
+		weak := gen.IfMatch(`W/"2"`)
+		rPut, err = tc.PutEventWithResponse(ctx, event.ID, &gen.PutEventParams{IfMatch: &weak}, dto)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, rPut.StatusCode())
+
+		ifMatch = `"2"`
+		rDel, err = tc.DeleteEventWithResponse(ctx, event.ID, &gen.DeleteEventParams{IfMatch: &ifMatch})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, rDel.StatusCode())
+
+		// PUT does not create events
+		rPut, err = tc.PutEventWithResponse(ctx, event.ID, &gen.PutEventParams{}, dto)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNotFound, rPut.StatusCode())
+		rDel, err = tc.DeleteEventWithResponse(ctx, event.ID, &gen.DeleteEventParams{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNotFound, rDel.StatusCode())
+	})
+
+	t.Run("Patch Event", func(t *testing.T) {
+		startTime := startTime.AddDate(0, 7, 0)
+		event, err := s.app.CreateEvent(ctx, "", "patched event", startTime, startTime.Add(time.Hour), "test", false)
+		require.NoError(t, err)
+		event.Notes = "kept"
+		require.NoError(t, s.app.UpdateEvent(ctx, event, false))
+		patch := func(body string, ifMatch *gen.IfMatch) *gen.PatchEventResponse {
+			r, err := tc.PatchEventWithBodyWithResponse(
+				ctx, event.ID, &gen.PatchEventParams{IfMatch: ifMatch}, "application/merge-patch+json",
+				strings.NewReader(body),
+			)
+			require.NoError(t, err)
+			return r
+		}
+
+		moved := startTime.Add(30 * time.Minute).Format(time.RFC3339)
+		ends := startTime.Add(90 * time.Minute).Format(time.RFC3339)
+		ifMatch := gen.IfMatch(`"2"`)
+		r := patch(`{"starts_at": "`+moved+`", "ends_at": "`+ends+`", "timezone": "Europe/Berlin", "version": 42}`, &ifMatch)
+		require.Equal(t, http.StatusOK, r.StatusCode())
+		require.Equal(t, `"3"`, r.HTTPResponse.Header.Get("ETag"))
+		rGet, err := tc.GetEventWithResponse(ctx, event.ID)
+		require.NoError(t, err)
+		require.True(t, startTime.Add(30*time.Minute).Equal(rGet.JSON200.StartsAt))
+		require.Equal(t, "kept", rGet.JSON200.Notes)
+		require.Equal(t, "patched event", rGet.JSON200.Title)
+		require.Equal(t, "Europe/Berlin", *rGet.JSON200.Timezone)
+
+		r = patch(`{"timezone": null, "notes": null}`, nil)
+		require.Equal(t, http.StatusOK, r.StatusCode())
+		rGet, err = tc.GetEventWithResponse(ctx, event.ID)
+		require.NoError(t, err)
+		require.Nil(t, rGet.JSON200.Timezone)
+		require.Equal(t, "", rGet.JSON200.Notes)
+
+		// the patched event is validated and stale versions are rejected
+		require.Equal(t, http.StatusBadRequest, patch(`{"ends_at": "`+startTime.Format(time.RFC3339)+`"}`, nil).StatusCode())
+		require.Equal(t, http.StatusBadRequest, patch(`{"title": null}`, nil).StatusCode())
+		require.Equal(t, http.StatusBadRequest, patch(`[]`, nil).StatusCode())
+		require.Equal(t, http.StatusPreconditionFailed, patch(`{"notes": "stale"}`, &ifMatch).StatusCode())
+
+		rJSON, err := tc.PatchEventWithBodyWithResponse(
+			ctx, event.ID, &gen.PatchEventParams{}, "application/json", strings.NewReader(`{"notes": "plain"}`),
+		)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnsupportedMediaType, rJSON.StatusCode())
+		rMissing, err := tc.PatchEventWithBodyWithResponse(
+			ctx, "missing", &gen.PatchEventParams{}, "application/merge-patch+json", strings.NewReader(`{}`),
+		)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNotFound, rMissing.StatusCode())
+	})
+
+	t.Run("Trash", func(t *testing.T) {
+		startTime := startTime.AddDate(0, 8, 0)
+		event, err := s.app.CreateEvent(ctx, "", "trashed event", startTime, startTime.Add(time.Hour), "trasher", false)
+		require.NoError(t, err)
+		rDel, err := tc.DeleteEventWithResponse(ctx, event.ID, &gen.DeleteEventParams{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, rDel.StatusCode())
+
+		owner := "trasher"
+		rList, err := tc.ListTrashWithResponse(ctx, &gen.ListTrashParams{OwnerId: &owner})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, rList.StatusCode())
+		require.Len(t, *rList.JSON200, 1)
+		require.Equal(t, event.ID, (*rList.JSON200)[0].Id)
+		require.NotNil(t, (*rList.JSON200)[0].DeletedAt)
+
+		stale := gen.IfMatch(`"1"`)
+		rRestore, err := tc.RestoreEventWithResponse(ctx, event.ID, &gen.RestoreEventParams{IfMatch: &stale})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusPreconditionFailed, rRestore.StatusCode())
+		rRestore, err = tc.RestoreEventWithResponse(ctx, event.ID, &gen.RestoreEventParams{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, rRestore.StatusCode())
+		require.Equal(t, `"3"`, rRestore.HTTPResponse.Header.Get("ETag"))
+		require.Nil(t, rRestore.JSON200.DeletedAt)
+		rGet, err := tc.GetEventWithResponse(ctx, event.ID)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, rGet.StatusCode())
+
+		rPurge, err := tc.PurgeEventWithResponse(ctx, event.ID, &gen.PurgeEventParams{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNotFound, rPurge.StatusCode())
+		rDel, err = tc.DeleteEventWithResponse(ctx, event.ID, &gen.DeleteEventParams{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, rDel.StatusCode())
+		rPurge, err = tc.PurgeEventWithResponse(ctx, event.ID, &gen.PurgeEventParams{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, rPurge.StatusCode())
+		rRestore, err = tc.RestoreEventWithResponse(ctx, event.ID, &gen.RestoreEventParams{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNotFound, rRestore.StatusCode())
+		rList, err = tc.ListTrashWithResponse(ctx, &gen.ListTrashParams{OwnerId: &owner})
+		require.NoError(t, err)
+		require.Len(t, *rList.JSON200, 0)
+	})
+
+	t.Run("History", func(t *testing.T) {
+		startTime := startTime.AddDate(0, 9, 0)
+		created := gen.CreateEventJSONRequestBody{
+			Title:    "audited event",
+			OwnerId:  "auditor",
+			StartsAt: startTime,
+			EndsAt:   startTime.Add(time.Hour),
+		}
+		rCreate, err := tc.CreateEventWithResponse(ctx, &gen.CreateEventParams{}, created)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, rCreate.StatusCode())
+		id := rCreate.JSON200.Id
+		updated := *rCreate.JSON200
+		updated.Title = "renamed event"
+		rPut, err := tc.PutEventWithResponse(ctx, id, &gen.PutEventParams{}, gen.PutEventJSONRequestBody(updated))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, rPut.StatusCode())
+		rDel, err := tc.DeleteEventWithResponse(ctx, id, &gen.DeleteEventParams{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, rDel.StatusCode())
+		rPurge, err := tc.PurgeEventWithResponse(ctx, id, &gen.PurgeEventParams{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, rPurge.StatusCode())
+
+		rHistory, err := tc.GetEventHistoryWithResponse(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, rHistory.StatusCode())
+		records := *rHistory.JSON200
+		require.Len(t, records, 4)
+		operations := make([]gen.AuditRecordOperation, 0, len(records))
+		for _, record := range records {
+			require.Equal(t, id, record.EventId)
+			require.Empty(t, record.Actor)
+			operations = append(operations, record.Operation)
+		}
+		require.Equal(t, []gen.AuditRecordOperation{"create", "update", "delete", "purge"}, operations)
+		require.Nil(t, records[0].Before)
+		require.Equal(t, "audited event", records[0].After.Title)
+		require.Equal(t, "audited event", records[1].Before.Title)
+		require.Equal(t, "renamed event", records[1].After.Title)
+		require.NotNil(t, records[2].After.DeletedAt)
+		require.Equal(t, int64(3), *records[3].Before.Version)
+		require.Nil(t, records[3].After)
+
+		rHistory, err = tc.GetEventHistoryWithResponse(ctx, "never-stored")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNotFound, rHistory.StatusCode())
+	})
+
 	t.Run("Create Event", func(t *testing.T) {
+		startTime := startTime.Add(3 * time.Hour)
 		expected := gen.Event{
 			AlertBefore: 0,
 			Title:       "today event",
@@ -104,7 +306,7 @@ func TestHttpServer(t *testing.T) {
 			StartsAt:    startTime,
 			EndsAt:      startTime.Add(time.Hour),
 		}
-		r, err := tc.CreateEventWithResponse(ctx, gen.CreateEventJSONRequestBody(expected))
+		r, err := tc.CreateEventWithResponse(ctx, &gen.CreateEventParams{}, gen.CreateEventJSONRequestBody(expected))
 		require.NoError(t, err)
 		require.Equal(t, r.StatusCode(), http.StatusOK)
 		event := *r.JSON200
@@ -116,6 +318,183 @@ func TestHttpServer(t *testing.T) {
 		eventsIdentical(t, *rGet.JSON200, event)
 	})
 
+	t.Run("Busy Slot", func(t *testing.T) {
+		startTime := startTime.Add(4 * time.Hour)
+		event, err := s.app.CreateEvent(ctx, "", "busy event", startTime, startTime.Add(time.Hour), "test", false)
+		require.NoError(t, err)
+
+		updated := *newEventDto(event)
+		updated.Notes = "moved by 30 minutes"
+		updated.StartsAt, updated.EndsAt = startTime.Add(30*time.Minute), startTime.Add(90*time.Minute)
+		rPut, err := tc.PutEventWithResponse(ctx, event.ID, &gen.PutEventParams{}, gen.PutEventJSONRequestBody(updated))
+		require.NoError(t, err)
+		require.Equal(t, rPut.StatusCode(), http.StatusOK)
+
+		overlapping := gen.CreateEventJSONRequestBody{
+			Title:    "overlapping event",
+			OwnerId:  "test",
+			StartsAt: startTime.Add(time.Hour),
+			EndsAt:   startTime.Add(2 * time.Hour),
+		}
+		r, err := tc.CreateEventWithResponse(ctx, &gen.CreateEventParams{}, overlapping)
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusConflict)
+
+		allow := gen.AllowOverlap(true)
+		r, err = tc.CreateEventWithResponse(ctx, &gen.CreateEventParams{AllowOverlap: &allow}, overlapping)
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusOK)
+
+		overlapping.OwnerId = "another owner"
+		r, err = tc.CreateEventWithResponse(ctx, &gen.CreateEventParams{}, overlapping)
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusOK)
+
+		// a weekly event clashing with the event in its third week is rejected
+		weekly := "FREQ=WEEKLY"
+		recurring := gen.CreateEventJSONRequestBody{
+			Title:      "weekly event",
+			OwnerId:    "test",
+			StartsAt:   startTime.AddDate(0, 0, -14),
+			EndsAt:     startTime.AddDate(0, 0, -14).Add(time.Hour),
+			Recurrence: &weekly,
+		}
+		r, err = tc.CreateEventWithResponse(ctx, &gen.CreateEventParams{}, recurring)
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusConflict)
+	})
+
+	t.Run("Attendees", func(t *testing.T) {
+		startTime := startTime.Add(6 * time.Hour)
+		event, err := s.app.CreateEvent(ctx, "", "meeting", startTime, startTime.Add(time.Hour), "test", false)
+		require.NoError(t, err)
+
+		r, err := tc.AddAttendeeWithResponse(ctx, event.ID, gen.AddAttendeeJSONRequestBody{UserId: "bob"})
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusOK)
+		require.Len(t, *r.JSON200.Attendees, 1)
+		require.Equal(t, gen.ResponseStatusNeedsAction, *(*r.JSON200.Attendees)[0].Status)
+
+		rsvp := gen.RespondToEventJSONRequestBody{Status: gen.ResponseStatusAccepted}
+		rResp, err := tc.RespondToEventWithResponse(ctx, event.ID, "bob", rsvp)
+		require.NoError(t, err)
+		require.Equal(t, rResp.StatusCode(), http.StatusOK)
+		require.Equal(t, gen.ResponseStatusAccepted, *(*rResp.JSON200.Attendees)[0].Status)
+
+		rResp, err = tc.RespondToEventWithResponse(ctx, event.ID, "carol", rsvp)
+		require.NoError(t, err)
+		require.Equal(t, rResp.StatusCode(), http.StatusNotFound)
+		rsvp.Status = "maybe"
+		rResp, err = tc.RespondToEventWithResponse(ctx, event.ID, "bob", rsvp)
+		require.NoError(t, err)
+		require.Equal(t, rResp.StatusCode(), http.StatusBadRequest)
+
+		stored, err := s.app.GetEvent(ctx, event.ID)
+		require.NoError(t, err)
+		alerts := models.NewAlerts(stored)
+		require.Len(t, alerts, 2)
+		require.Equal(t, "bob", alerts[1].Addressee)
+
+		rDel, err := tc.RemoveAttendeeWithResponse(ctx, event.ID, "bob")
+		require.NoError(t, err)
+		require.Equal(t, rDel.StatusCode(), http.StatusOK)
+		require.Nil(t, rDel.JSON200.Attendees)
+	})
+
+	t.Run("Free Busy", func(t *testing.T) {
+		day := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+		at := func(h, m int) time.Time { return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute) }
+		_, err := s.app.CreateEvent(ctx, "", "review", at(9, 0), at(10, 0), "fb-alice", false)
+		require.NoError(t, err)
+		_, err = s.app.CreateEvent(ctx, "", "planning", at(9, 30), at(11, 0), "fb-alice", true)
+		require.NoError(t, err)
+		standup, err := models.NewEvent("", "standup", at(16, 0), at(17, 0), "fb-alice")
+		require.NoError(t, err)
+		standup.Recurrence, _ = models.ParseRRule("FREQ=DAILY")
+		require.NoError(t, s.app.AddEvent(ctx, standup, false))
+		_, err = s.app.CreateEvent(ctx, "", "lunch", at(13, 0), at(14, 0), "fb-bob", false)
+		require.NoError(t, err)
+		holiday, err := models.NewAllDayEvent("", "holiday", day, day, "fb-bob")
+		require.NoError(t, err)
+		require.NoError(t, s.app.AddEvent(ctx, holiday, false))
+
+		minFree := 3600
+		owners := []string{"fb-alice", "fb-bob"}
+		params := &gen.GetFreeBusyParams{OwnerId: owners, From: at(8, 0), To: at(18, 0), MinFree: &minFree}
+		r, err := tc.GetFreeBusyWithResponse(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusOK)
+		freeBusy := *r.JSON200
+		require.Equal(t, []gen.OwnerBusy{
+			{OwnerId: "fb-alice", Intervals: []gen.Interval{
+				{Start: at(9, 0), End: at(11, 0)},
+				{Start: at(16, 0), End: at(17, 0)},
+			}},
+			{OwnerId: "fb-bob", Intervals: []gen.Interval{{Start: at(13, 0), End: at(14, 0)}}},
+		}, freeBusy.Busy)
+		require.Equal(t, []gen.Interval{
+			{Start: at(8, 0), End: at(9, 0)},
+			{Start: at(11, 0), End: at(13, 0)},
+			{Start: at(14, 0), End: at(16, 0)},
+			{Start: at(17, 0), End: at(18, 0)},
+		}, freeBusy.Free)
+
+		params.From = params.To
+		r, err = tc.GetFreeBusyWithResponse(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusBadRequest)
+	})
+
+	t.Run("Find Slots", func(t *testing.T) {
+		day := time.Date(2021, 6, 8, 0, 0, 0, 0, time.UTC)
+		at := func(h, m int) time.Time { return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute) }
+		_, err := s.app.CreateEvent(ctx, "", "review", at(9, 0), at(10, 30), "slot-alice", false)
+		require.NoError(t, err)
+		_, err = s.app.CreateEvent(ctx, "", "lunch", at(12, 0), at(13, 0), "slot-bob", false)
+		require.NoError(t, err)
+
+		workStart, workEnd, limit := "09:00", "14:00", 3
+		params := &gen.FindSlotsParams{
+			Attendee:  []string{"slot-alice", "slot-bob"},
+			Duration:  45 * 60,
+			From:      day,
+			To:        day.AddDate(0, 0, 2),
+			WorkStart: &workStart,
+			WorkEnd:   &workEnd,
+			Limit:     &limit,
+		}
+		r, err := tc.FindSlotsWithResponse(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusOK)
+		require.Equal(t, []gen.Interval{
+			{Start: at(10, 30), End: at(11, 15)},
+			{Start: at(11, 15), End: at(12, 0)},
+			{Start: at(13, 0), End: at(13, 45)},
+		}, *r.JSON200)
+
+		workDays := []string{"WE"}
+		params.WorkDay = &workDays
+		r, err = tc.FindSlotsWithResponse(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusOK)
+		require.Len(t, *r.JSON200, 3)
+		require.True(t, (*r.JSON200)[0].Start.Equal(at(33, 0)))
+
+		// larger limits are capped
+		limit = 10 * models.MaxSlotLimit
+		params.WorkDay, params.WorkStart, params.WorkEnd, params.Duration = nil, nil, nil, 15*60
+		r, err = tc.FindSlotsWithResponse(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusOK)
+		require.Len(t, *r.JSON200, models.MaxSlotLimit)
+
+		timezone := "Mars/Olympus"
+		params.Timezone = &timezone
+		r, err = tc.FindSlotsWithResponse(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusBadRequest)
+	})
+
 	t.Run("List Events", func(t *testing.T) {
 		startTime := startTime.AddDate(0, 1, 0)
 		event, err := s.app.CreateEvent(
@@ -124,6 +503,7 @@ func TestHttpServer(t *testing.T) {
 			startTime,
 			startTime.Add(time.Hour),
 			"test",
+			false,
 		)
 		require.NoError(t, err)
 
@@ -136,9 +516,10 @@ func TestHttpServer(t *testing.T) {
 			Title:       event.Title,
 		}
 
+		startFrom := startDate.AddDate(0, 1, 0)
 		params := &gen.ListEventsParams{
 			Agenda:    "daily",
-			StartFrom: startDate.AddDate(0, 1, 0),
+			StartFrom: &startFrom,
 		}
 		r, err := tc.ListEventsWithResponse(ctx, params)
 		require.NoError(t, err)
@@ -146,16 +527,469 @@ func TestHttpServer(t *testing.T) {
 		events := *r.JSON200
 		require.Len(t, events, 1)
 		eventsIdentical(t, events[0], expected)
+
+		// the event starts after midnight in Tokyo, i.e. on the next day there
+		timezone := "Asia/Tokyo"
+		params.Timezone = &timezone
+		r, err = tc.ListEventsWithResponse(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusOK)
+		require.Len(t, *r.JSON200, 0)
+		startFrom = startFrom.AddDate(0, 0, 1)
+		r, err = tc.ListEventsWithResponse(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusOK)
+		require.Len(t, *r.JSON200, 1)
+
+		timezone = "Mars/Olympus"
+		r, err = tc.ListEventsWithResponse(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusBadRequest)
+		params.Timezone = nil
+
+		stranger := "stranger"
+		params.OwnerId = &stranger
+		r, err = tc.ListEventsWithResponse(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusOK)
+		require.Len(t, *r.JSON200, 0)
+	})
+
+	t.Run("List Event Range", func(t *testing.T) {
+		startTime := startTime.AddDate(0, 5, 0)
+		for i := 0; i < 3; i++ {
+			_, err := s.app.CreateEvent(
+				ctx, "", "ranged", startTime.AddDate(0, 0, 7*i), startTime.AddDate(0, 0, 7*i).Add(time.Hour), "ranger", false,
+			)
+			require.NoError(t, err)
+		}
+
+		owner, pageSize := "ranger", 2
+		params := &gen.ListEventRangeParams{
+			From:     startTime,
+			To:       startTime.AddDate(0, 3, 0),
+			OwnerId:  &owner,
+			PageSize: &pageSize,
+		}
+		r, err := tc.ListEventRangeWithResponse(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusOK)
+		require.Len(t, r.JSON200.Events, 2)
+		require.NotNil(t, r.JSON200.NextPageToken)
+
+		params.PageToken = r.JSON200.NextPageToken
+		r, err = tc.ListEventRangeWithResponse(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusOK)
+		require.Len(t, r.JSON200.Events, 1)
+		require.True(t, r.JSON200.Events[0].StartsAt.Equal(startTime.AddDate(0, 0, 14)))
+		require.Nil(t, r.JSON200.NextPageToken)
+
+		invalid := "?"
+		params.PageToken = &invalid
+		r, err = tc.ListEventRangeWithResponse(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusBadRequest)
+	})
+
+	t.Run("Search Events", func(t *testing.T) {
+		startTime := startTime.AddDate(0, 6, 0)
+		_, err := s.app.CreateEvent(ctx, "", "Budget review", startTime, startTime.Add(time.Hour), "searcher", false)
+		require.NoError(t, err)
+
+		owner := "searcher"
+		params := &gen.SearchEventsParams{Q: "budget", OwnerId: &owner}
+		r, err := tc.SearchEventsWithResponse(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusOK)
+		require.Len(t, *r.JSON200, 1)
+		require.Equal(t, "Budget review", (*r.JSON200)[0].Title)
+
+		from, to := startTime.AddDate(0, 0, 1), startTime.AddDate(0, 0, 2)
+		params.From, params.To = &from, &to
+		r, err = tc.SearchEventsWithResponse(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusOK)
+		require.Len(t, *r.JSON200, 0)
+
+		params.To = nil
+		r, err = tc.SearchEventsWithResponse(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusBadRequest)
+	})
+
+	t.Run("List Recurring Events", func(t *testing.T) {
+		startTime := startTime.AddDate(0, 2, 0)
+		rule := "FREQ=DAILY;INTERVAL=2;COUNT=3"
+		exDates := []time.Time{startTime.AddDate(0, 0, 2)}
+		r, err := tc.CreateEventWithResponse(ctx, &gen.CreateEventParams{}, gen.CreateEventJSONRequestBody{
+			Title:      "every other day",
+			OwnerId:    "test",
+			StartsAt:   startTime,
+			EndsAt:     startTime.Add(time.Hour),
+			Recurrence: &rule,
+			Exdates:    &exDates,
+		})
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusOK)
+		require.Equal(t, rule, *r.JSON200.Recurrence)
+
+		params := &gen.ListEventsParams{
+			Agenda:    "weekly",
+			StartFrom: &startTime,
+		}
+		rList, err := tc.ListEventsWithResponse(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, rList.StatusCode(), http.StatusOK)
+		events := *rList.JSON200
+		require.Len(t, events, 2)
+		require.Equal(t, r.JSON200.Id, events[1].Id)
+		require.True(t, events[1].OccurrenceStart.Equal(startTime.AddDate(0, 0, 4)))
+
+		// the series starts on Monday, a week from Tuesday has its first occurrence only
+		tuesday := "TU"
+		params.WeekStart = &tuesday
+		rList, err = tc.ListEventsWithResponse(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, rList.StatusCode(), http.StatusOK)
+		require.Len(t, *rList.JSON200, 1)
+
+		isoWeek := "2021-W09"
+		params.Week = &isoWeek
+		rList, err = tc.ListEventsWithResponse(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, rList.StatusCode(), http.StatusBadRequest)
+		params.StartFrom, params.WeekStart = nil, nil
+		rList, err = tc.ListEventsWithResponse(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, rList.StatusCode(), http.StatusOK)
+		require.Len(t, *rList.JSON200, 2)
+
+		invalid := "FREQ=DAILY;BYSETPOS=1"
+		r, err = tc.CreateEventWithResponse(ctx, &gen.CreateEventParams{}, gen.CreateEventJSONRequestBody{
+			Title:      "invalid rule",
+			OwnerId:    "test",
+			StartsAt:   startTime,
+			EndsAt:     startTime.Add(time.Hour),
+			Recurrence: &invalid,
+		})
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusBadRequest)
+	})
+
+	t.Run("Export Events", func(t *testing.T) {
+		startTime := startTime.AddDate(0, 3, 0)
+		owner := "exporter"
+		rule := "FREQ=WEEKLY;COUNT=4"
+		r, err := tc.CreateEventWithResponse(ctx, &gen.CreateEventParams{}, gen.CreateEventJSONRequestBody{
+			Title:       "weekly sync",
+			Notes:       "agenda: budget, hiring\nbring laptops",
+			OwnerId:     owner,
+			StartsAt:    startTime,
+			EndsAt:      startTime.Add(time.Hour),
+			AlertBefore: 900,
+			Recurrence:  &rule,
+		})
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusOK)
+
+		rFeed, err := tc.ExportEventsWithResponse(ctx, &gen.ExportEventsParams{OwnerId: &owner})
+		require.NoError(t, err)
+		require.Equal(t, rFeed.StatusCode(), http.StatusOK)
+		require.Contains(t, rFeed.HTTPResponse.Header.Get("Content-Type"), "text/calendar")
+		feed := string(rFeed.Body)
+		require.True(t, strings.HasPrefix(feed, "BEGIN:VCALENDAR\r\n"))
+		require.Equal(t, 1, strings.Count(feed, "BEGIN:VEVENT"))
+		require.Contains(t, feed, "UID:"+r.JSON200.Id+"\r\n")
+		require.Contains(t, feed, "DTSTART:20210401T160000Z\r\n")
+		require.Contains(t, feed, "RRULE:"+rule+"\r\n")
+		require.Contains(t, feed, `DESCRIPTION:agenda: budget\, hiring\nbring laptops`)
+		require.Contains(t, feed, "TRIGGER:-PT15M\r\n")
+
+		agenda := gen.ExportEventsParamsAgenda("monthly")
+		rFeed, err = tc.ExportEventsWithResponse(ctx, &gen.ExportEventsParams{
+			Agenda:    &agenda,
+			StartFrom: &startTime,
+			OwnerId:   &owner,
+		})
+		require.NoError(t, err)
+		require.Equal(t, rFeed.StatusCode(), http.StatusOK)
+		feed = string(rFeed.Body)
+		require.Equal(t, 4, strings.Count(feed, "BEGIN:VEVENT"))
+		require.Equal(t, 4, strings.Count(feed, "RECURRENCE-ID:"))
+		require.NotContains(t, feed, "RRULE:")
+
+		rFeed, err = tc.ExportEventsWithResponse(ctx, &gen.ExportEventsParams{})
+		require.NoError(t, err)
+		require.Equal(t, rFeed.StatusCode(), http.StatusBadRequest)
+	})
+
+	t.Run("Import Events", func(t *testing.T) {
+		owner := "importer"
+		feed := strings.Join([]string{
+			"BEGIN:VCALENDAR",
+			"VERSION:2.0",
+			"PRODID:-//Other//Calendar//EN",
+			"BEGIN:VEVENT",
+			"UID:planning@example.com",
+			"DTSTART;TZID=Europe/Berlin:20210510T090000",
+			"DTEND;TZID=Europe/Berlin:20210510T100000",
+			"SUMMARY:Planning\\, Q3",
+			"DESCRIPTION:first line\\nsecond",
+			"  line",
+			"RRULE:FREQ=WEEKLY;COUNT=3",
+			"BEGIN:VALARM",
+			"ACTION:DISPLAY",
+			"TRIGGER:-PT10M",
+			"END:VALARM",
+			"END:VEVENT",
+			"BEGIN:VEVENT",
+			"UID:holiday@example.com",
+			"DTSTART;VALUE=DATE:20210513",
+			"SUMMARY:Holiday",
+			"END:VEVENT",
+			"BEGIN:VEVENT",
+			"UID:broken@example.com",
+			"DTSTART:20210510T100000Z",
+			"DTEND:20210510T090000Z",
+			"END:VEVENT",
+			"BEGIN:VEVENT",
+			"DTSTART:20210510T100000Z",
+			"END:VEVENT",
+			"END:VCALENDAR",
+		}, "\r\n")
+		params := &gen.ImportEventsParams{OwnerId: &owner}
+		r, err := tc.ImportEventsWithBodyWithResponse(ctx, params, "text/calendar", strings.NewReader(feed))
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusOK)
+		results := *r.JSON200
+		require.Len(t, results, 4)
+		require.True(t, results[0].Imported)
+		require.True(t, results[1].Imported)
+		require.False(t, results[2].Imported)
+		require.Equal(t, 22, results[2].Line)
+		require.False(t, results[3].Imported)
+
+		rGet, err := tc.GetEventWithResponse(ctx, *results[0].Id)
+		require.NoError(t, err)
+		require.Equal(t, rGet.StatusCode(), http.StatusOK)
+		planning := *rGet.JSON200
+		require.Equal(t, "Planning, Q3", planning.Title)
+		require.Equal(t, "first line\nsecond line", planning.Notes)
+		require.Equal(t, owner, planning.OwnerId)
+		require.Equal(t, 600, planning.AlertBefore)
+		require.True(t, planning.StartsAt.Equal(time.Date(2021, 5, 10, 7, 0, 0, 0, time.UTC)))
+		require.Equal(t, "FREQ=WEEKLY;COUNT=3", *planning.Recurrence)
+
+		rGet, err = tc.GetEventWithResponse(ctx, *results[1].Id)
+		require.NoError(t, err)
+		require.True(t, *rGet.JSON200.AllDay)
+		require.True(t, rGet.JSON200.EndsAt.Equal(time.Date(2021, 5, 14, 0, 0, 0, 0, time.UTC)))
+
+		r, err = tc.ImportEventsWithBodyWithResponse(ctx, params, "text/calendar", strings.NewReader(feed))
+		require.NoError(t, err)
+		require.Equal(t, *results[0].Id, *(*r.JSON200)[0].Id)
+		rFeed, err := tc.ExportEventsWithResponse(ctx, &gen.ExportEventsParams{OwnerId: &owner})
+		require.NoError(t, err)
+		require.Equal(t, 2, strings.Count(string(rFeed.Body), "BEGIN:VEVENT"))
+
+		r, err = tc.ImportEventsWithBodyWithResponse(ctx, params, "text/calendar", bytes.NewReader(rFeed.Body))
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusOK)
+		require.Equal(t, *results[0].Id, *(*r.JSON200)[0].Id)
+		require.Equal(t, *results[1].Id, *(*r.JSON200)[1].Id)
+
+		// other owners importing the same feeds get events of their own
+		other := "other importer"
+		otherParams := &gen.ImportEventsParams{OwnerId: &other}
+		for _, body := range [][]byte{[]byte(feed), rFeed.Body} {
+			r, err = tc.ImportEventsWithBodyWithResponse(ctx, otherParams, "text/calendar", bytes.NewReader(body))
+			require.NoError(t, err)
+			require.Equal(t, r.StatusCode(), http.StatusOK)
+			require.True(t, (*r.JSON200)[0].Imported)
+			require.NotEqual(t, *results[0].Id, *(*r.JSON200)[0].Id)
+		}
+		rGet, err = tc.GetEventWithResponse(ctx, *results[0].Id)
+		require.NoError(t, err)
+		require.Equal(t, owner, rGet.JSON200.OwnerId)
+		rFeed, err = tc.ExportEventsWithResponse(ctx, &gen.ExportEventsParams{OwnerId: &other})
+		require.NoError(t, err)
+		// the feed and its export have different UIDs, both are imported
+		require.Equal(t, 4, strings.Count(string(rFeed.Body), "BEGIN:VEVENT"))
+
+		r, err = tc.ImportEventsWithBodyWithResponse(ctx, params, "text/calendar", strings.NewReader("SUMMARY:x"))
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusBadRequest)
+	})
+}
+
+func TestConflictStatus(t *testing.T) {
+	s := makeServer()
+	conflict := fmt.Errorf("%w: event is not at version 1", models.ErrConflict)
+	for ifMatch, status := range map[string]int{
+		`"1"`: http.StatusPreconditionFailed,
+		"*":   http.StatusConflict,
+		"":    http.StatusConflict,
+	} {
+		r := httptest.NewRequest(http.MethodPut, "/api/calendar/events/42", nil)
+		if ifMatch != "" {
+			r.Header.Set("If-Match", ifMatch)
+		}
+		w := httptest.NewRecorder()
+		s.AppError(conflict, w, r)
+		require.Equal(t, status, w.Code, ifMatch)
+	}
+}
+
+// failingAudit - an audit log which is unavailable
+type failingAudit struct{}
+
+func (failingAudit) Append(ctx context.Context, r *models.AuditRecord) error {
+	return fmt.Errorf("%w: audit log is unavailable", models.ErrDataError)
+}
+
+func (failingAudit) History(ctx context.Context, eventID string) ([]*models.AuditRecord, error) {
+	return nil, fmt.Errorf("%w: audit log is unavailable", models.ErrDataError)
+}
+
+func TestHttpAuditFailure(t *testing.T) {
+	ctx := context.Background()
+	s := makeServer()
+	s.app.SetAudit(failingAudit{})
+	ts := httptest.NewServer(s.buildRouter())
+	tc, _ := gen.NewClientWithResponses(ts.URL + "/api")
+
+	startTime := time.Date(2021, 1, 1, 9, 0, 0, 0, time.UTC)
+	event, err := models.NewEvent("", "unaudited event", startTime, startTime.Add(time.Hour), "auditor")
+	require.NoError(t, err)
+	require.NoError(t, s.app.AddEvent(ctx, event, false))
+
+	// the changes are stored and reported so even though the audit log misses them
+	rDel, err := tc.DeleteEventWithResponse(ctx, event.ID, &gen.DeleteEventParams{})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rDel.StatusCode())
+	rTrash, err := tc.ListTrashWithResponse(ctx, &gen.ListTrashParams{})
+	require.NoError(t, err)
+	require.Len(t, *rTrash.JSON200, 1)
+}
+
+func TestHttpAuth(t *testing.T) {
+	ctx := context.Background()
+	s := makeServer()
+	s.verifier, _ = auth.NewVerifier(common.AuthConf{HMACSecret: "secret"})
+	ts := httptest.NewServer(s.buildRouter())
+
+	startTime := time.Date(2021, 1, 1, 8, 0, 0, 0, time.UTC)
+	body := gen.CreateEventJSONRequestBody{
+		Title:    "today event",
+		OwnerId:  "someone else",
+		StartsAt: startTime,
+		EndsAt:   startTime.Add(time.Hour),
+	}
+	t.Run("No Token", func(t *testing.T) {
+		tc, _ := gen.NewClientWithResponses(ts.URL + "/api")
+		r, err := tc.CreateEventWithResponse(ctx, &gen.CreateEventParams{}, body)
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusUnauthorized)
+	})
+
+	t.Run("Bad Token", func(t *testing.T) {
+		tc, _ := gen.NewClientWithResponses(ts.URL+"/api", withToken(t, "alice", "wrong secret"))
+		r, err := tc.CreateEventWithResponse(ctx, &gen.CreateEventParams{}, body)
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusUnauthorized)
+
+		// tokens without the expiration claim never expire so they are rejected
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "alice"}).SignedString([]byte("secret"))
+		require.NoError(t, err)
+		tc, _ = gen.NewClientWithResponses(ts.URL+"/api", gen.WithRequestEditorFn(
+			func(ctx context.Context, req *http.Request) error {
+				req.Header.Set("Authorization", "Bearer "+token)
+				return nil
+			},
+		))
+		r, err = tc.CreateEventWithResponse(ctx, &gen.CreateEventParams{}, body)
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusUnauthorized)
+	})
+
+	t.Run("Owner From Token", func(t *testing.T) {
+		tc, _ := gen.NewClientWithResponses(ts.URL+"/api", withToken(t, "alice", "secret"))
+		r, err := tc.CreateEventWithResponse(ctx, &gen.CreateEventParams{}, body)
+		require.NoError(t, err)
+		require.Equal(t, r.StatusCode(), http.StatusOK)
+		require.Equal(t, "alice", r.JSON200.OwnerId)
+	})
+
+	t.Run("Edit Rights", func(t *testing.T) {
+		startTime := startTime.Add(time.Hour)
+		event, err := s.app.CreateEvent(ctx, "", "alice event", startTime, startTime.Add(time.Hour), "alice", false)
+		require.NoError(t, err)
+		dto := *newEventDto(event)
+		dto.Title = "renamed event"
+
+		bob, _ := gen.NewClientWithResponses(ts.URL+"/api", withToken(t, "bob", "secret"))
+		rPut, err := bob.PutEventWithResponse(ctx, event.ID, &gen.PutEventParams{}, gen.PutEventJSONRequestBody(dto))
+		require.NoError(t, err)
+		require.Equal(t, rPut.StatusCode(), http.StatusForbidden)
+		rDel, err := bob.DeleteEventWithResponse(ctx, event.ID, &gen.DeleteEventParams{})
+		require.NoError(t, err)
+		require.Equal(t, rDel.StatusCode(), http.StatusForbidden)
+
+		assistant, _ := gen.NewClientWithResponses(ts.URL+"/api", withToken(t, "carol", "secret", "alice"))
+		rPut, err = assistant.PutEventWithResponse(ctx, event.ID, &gen.PutEventParams{}, gen.PutEventJSONRequestBody(dto))
+		require.NoError(t, err)
+		require.Equal(t, rPut.StatusCode(), http.StatusOK)
+		require.Equal(t, "alice", rPut.JSON200.OwnerId)
+		rDel, err = assistant.DeleteEventWithResponse(ctx, event.ID, &gen.DeleteEventParams{})
+		require.NoError(t, err)
+		require.Equal(t, rDel.StatusCode(), http.StatusOK)
+
+		rHistory, err := bob.GetEventHistoryWithResponse(ctx, event.ID)
+		require.NoError(t, err)
+		require.Equal(t, rHistory.StatusCode(), http.StatusForbidden)
+		rHistory, err = assistant.GetEventHistoryWithResponse(ctx, event.ID)
+		require.NoError(t, err)
+		require.Equal(t, rHistory.StatusCode(), http.StatusOK)
+		records := *rHistory.JSON200
+		require.Len(t, records, 3)
+		require.Empty(t, records[0].Actor)
+		require.Equal(t, "carol", records[1].Actor)
+		require.Equal(t, "carol", records[2].Actor)
+
+		alice := "alice"
+		rFeed, err := bob.ExportEventsWithResponse(ctx, &gen.ExportEventsParams{OwnerId: &alice})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusForbidden, rFeed.StatusCode())
+		rFeed, err = assistant.ExportEventsWithResponse(ctx, &gen.ExportEventsParams{OwnerId: &alice})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, rFeed.StatusCode())
+	})
+}
+
+func withToken(t *testing.T, subject, secret string, grants ...string) gen.ClientOption {
+	claims := jwt.MapClaims{"sub": subject, "exp": time.Now().Add(time.Hour).Unix(), "grants": grants}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	require.NoError(t, err)
+	return gen.WithRequestEditorFn(func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
 	})
 }
 
 func makeServer() *HTTPServer {
 	log := common.NewLogger("debug", "")
 
-	eventsRepo, err := storage.NewStorage("in-memory")
+	conf := common.StorageConf{Kind: "in-memory"}
+	eventsRepo, err := storage.NewStorage(conf)
 	if err != nil {
 		log.Fatalf("storage was not created: %v", err)
 	}
+	audit, err := storage.NewAuditStorage(conf, eventsRepo)
+	if err != nil {
+		log.Fatalf("audit log was not created: %v", err)
+	}
 	calendar := app.New(log, eventsRepo)
-	return NewServer("", "", log, calendar)
+	calendar.SetAudit(audit)
+	return NewServer("", "", log, calendar, nil)
 }
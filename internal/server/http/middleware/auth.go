@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/VladNF/calendar/internal/auth"
+	"github.com/VladNF/calendar/internal/common"
+)
+
+func AuthMiddleware(verifier *auth.Verifier, log common.Logger) func(h http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			const prefix = "bearer "
+			if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+				log.Warnf("Unauthorized - %v %v has no bearer token", r.Method, r.URL.EscapedPath())
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			identity, err := verifier.Verify(header[len(prefix):])
+			if err != nil {
+				log.Warnf("Unauthorized - %v", err)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			h.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), identity)))
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package serverhttp
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/VladNF/calendar/internal/models"
+	"github.com/VladNF/calendar/internal/server/http/gen"
+)
+
+// mergePatchType - the media type of JSON Merge Patch bodies
+const mergePatchType = "application/merge-patch+json"
+
+// requiredFields may be changed by a merge patch but not removed
+var requiredFields = []string{"title", "starts_at", "ends_at", "owner_id"}
+
+// mergePatch applies the patch to the target as RFC 7386 describes it, objects are merged member by member,
+// a null removes the member and any other value replaces it
+func mergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+		} else {
+			targetObject[name] = mergePatch(targetObject[name], value)
+		}
+	}
+	return targetObject
+}
+
+// applyEventPatch changes the event with the merge patch of its JSON form, read-only fields of the patch are ignored
+func applyEventPatch(event *models.Event, data []byte) error {
+	var patch map[string]interface{}
+	if err := json.Unmarshal(data, &patch); err != nil || patch == nil {
+		return fmt.Errorf("%w: the merge patch must be a JSON object", models.ErrValueError)
+	}
+	for _, name := range requiredFields {
+		if value, ok := patch[name]; ok && value == nil {
+			return fmt.Errorf("%w: %s may not be removed", models.ErrValueError, name)
+		}
+	}
+
+	data, err := json.Marshal(newEventDto(event))
+	if err != nil {
+		return err
+	}
+	var target interface{}
+	if err = json.Unmarshal(data, &target); err != nil {
+		return err
+	}
+	if data, err = json.Marshal(mergePatch(target, patch)); err != nil {
+		return err
+	}
+	dto := gen.Event{}
+	if err = json.Unmarshal(data, &dto); err != nil {
+		return fmt.Errorf("%w: %v", models.ErrValueError, err)
+	}
+	patched, err := newEventModel(event.ID, &dto)
+	if err != nil {
+		return err
+	}
+	*event = *patched
+	return nil
+}
@@ -2,14 +2,18 @@ package serverhttp
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"net"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/VladNF/calendar/internal/app"
+	"github.com/VladNF/calendar/internal/auth"
 	"github.com/VladNF/calendar/internal/common"
+	"github.com/VladNF/calendar/internal/ical"
 	"github.com/VladNF/calendar/internal/models"
 	"github.com/VladNF/calendar/internal/server/http/gen"
 	"github.com/VladNF/calendar/internal/server/http/middleware"
@@ -18,144 +22,604 @@ import (
 )
 
 type HTTPServer struct {
-	app    *app.App
-	host   string
-	port   string
-	log    common.Logger
-	server http.Server
+	app      *app.App
+	host     string
+	port     string
+	log      common.Logger
+	server   http.Server
+	verifier *auth.Verifier
 }
 
-func (s *HTTPServer) CreateEvent(w http.ResponseWriter, r *http.Request) {
+func newEventDto(e *models.Event) *gen.Event {
+	dto := &gen.Event{
+		AlertBefore: int(e.AlertBefore.Seconds()),
+		EndsAt:      e.EndsAt,
+		Id:          e.ID,
+		Notes:       e.Notes,
+		OwnerId:     e.OwnerID,
+		StartsAt:    e.StartsAt,
+		Title:       e.Title,
+	}
+	if e.AllDay {
+		dto.AllDay = &e.AllDay
+	}
+	if e.IsRecurring() {
+		rule, exDates := e.Recurrence.String(), e.Recurrence.ExDates
+		dto.Recurrence, dto.Exdates = &rule, &exDates
+	}
+	if !e.OccurrenceStart.IsZero() {
+		dto.OccurrenceStart = &e.OccurrenceStart
+	}
+	if e.Timezone != nil {
+		timezone := e.Timezone.String()
+		dto.Timezone = &timezone
+	}
+	if e.Version != 0 {
+		dto.Version = &e.Version
+	}
+	if !e.UpdatedAt.IsZero() {
+		dto.UpdatedAt = &e.UpdatedAt
+	}
+	if e.IsDeleted() {
+		dto.DeletedAt = &e.DeletedAt
+	}
+	if len(e.Attendees) > 0 {
+		attendees := make([]gen.Attendee, 0, len(e.Attendees))
+		for _, a := range e.Attendees {
+			status := gen.ResponseStatus(a.Status)
+			attendees = append(attendees, gen.Attendee{UserId: a.UserID, Status: &status})
+		}
+		dto.Attendees = &attendees
+	}
+	return dto
+}
+
+func newAuditRecordDto(r *models.AuditRecord) gen.AuditRecord {
+	dto := gen.AuditRecord{
+		Actor:     r.Actor,
+		At:        r.At,
+		EventId:   r.EventID,
+		Operation: gen.AuditRecordOperation(r.Op),
+	}
+	if r.Before != nil {
+		dto.Before = newEventDto(r.Before)
+	}
+	if r.After != nil {
+		dto.After = newEventDto(r.After)
+	}
+	return dto
+}
+
+func allowOverlap(param *gen.AllowOverlap) bool {
+	return param != nil && bool(*param)
+}
+
+// etag is the strong entity tag of the version of an event
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ifMatchVersion returns the version of the If-Match entity tag, it is zero when the header is missing
+// or matches any version
+func ifMatchVersion(param *gen.IfMatch) (int64, error) {
+	if param == nil || *param == "" || *param == "*" {
+		return 0, nil
+	}
+	value := string(*param)
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return 0, fmt.Errorf("%w: If-Match must be a single strong entity tag", models.ErrValueError)
+	}
+	version, err := strconv.ParseInt(value[1:len(value)-1], 10, 64)
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("%w: If-Match is not an event version", models.ErrValueError)
+	}
+	return version, nil
+}
+
+func newEventModel(id string, dto *gen.Event) (*models.Event, error) {
+	newEvent := models.NewEvent
+	if dto.AllDay != nil && *dto.AllDay {
+		newEvent = models.NewAllDayEvent
+	}
+	event, err := newEvent(id, dto.Title, dto.StartsAt, dto.EndsAt, dto.OwnerId)
+	if err != nil {
+		return nil, err
+	}
+	event.AlertBefore = time.Duration(dto.AlertBefore * 1_000_000_000)
+	event.Notes = dto.Notes
+	if dto.Timezone != nil && *dto.Timezone != "" {
+		if event.Timezone, err = models.LoadTimezone(*dto.Timezone); err != nil {
+			return nil, err
+		}
+	}
+	if dto.Recurrence != nil && *dto.Recurrence != "" {
+		if event.Recurrence, err = models.ParseRRule(*dto.Recurrence); err != nil {
+			return nil, err
+		}
+		if dto.Exdates != nil {
+			event.Recurrence.ExDates = *dto.Exdates
+		}
+	}
+	return event, nil
+}
+
+func (s *HTTPServer) CreateEvent(w http.ResponseWriter, r *http.Request, params gen.CreateEventParams) {
 	eventDto := gen.Event{}
 	if err := render.Decode(r, &eventDto); err != nil {
 		s.BadRequest(err, w, r)
 		return
 	}
 
-	event, err := models.NewEvent("", eventDto.Title, eventDto.StartsAt, eventDto.EndsAt, eventDto.OwnerId)
+	event, err := newEventModel("", &eventDto)
 	if err != nil {
 		s.BadRequest(err, w, r)
 		return
 	}
-	event.AlertBefore = time.Duration(eventDto.AlertBefore * 1_000_000_000)
-	event.Notes = eventDto.Notes
-	if err = s.app.UpdateEvent(r.Context(), event); err != nil {
-		s.InternalError(err, w, r)
+	err = s.app.AddEvent(r.Context(), event, allowOverlap(params.AllowOverlap))
+	s.respondWithEvent(w, r, event, err)
+}
+
+func (s *HTTPServer) AddAttendee(w http.ResponseWriter, r *http.Request, id string) {
+	attendee := gen.Attendee{}
+	if err := render.Decode(r, &attendee); err != nil {
+		s.BadRequest(err, w, r)
 		return
 	}
-	render.Respond(w, r, &gen.Event{
-		AlertBefore: int(event.AlertBefore.Seconds()),
-		EndsAt:      event.EndsAt,
-		Id:          event.ID,
-		Notes:       event.Notes,
-		OwnerId:     event.OwnerID,
-		StartsAt:    event.StartsAt,
-		Title:       event.Title,
-	})
+	e, err := s.app.AddAttendee(r.Context(), id, attendee.UserId)
+	s.respondWithEvent(w, r, e, err)
 }
 
-func (s *HTTPServer) ListEvents(w http.ResponseWriter, r *http.Request, params gen.ListEventsParams) {
-	var events []*models.Event
-	var err error
-	switch params.Agenda {
+func (s *HTTPServer) RemoveAttendee(w http.ResponseWriter, r *http.Request, id string, userID string) {
+	e, err := s.app.RemoveAttendee(r.Context(), id, userID)
+	s.respondWithEvent(w, r, e, err)
+}
+
+func (s *HTTPServer) RespondToEvent(w http.ResponseWriter, r *http.Request, id string, userID string) {
+	rsvp := gen.Rsvp{}
+	if err := render.Decode(r, &rsvp); err != nil {
+		s.BadRequest(err, w, r)
+		return
+	}
+	status, err := models.ParseResponseStatus(string(rsvp.Status))
+	if err != nil {
+		s.BadRequest(err, w, r)
+		return
+	}
+	e, err := s.app.RespondToEvent(r.Context(), id, userID, status)
+	s.respondWithEvent(w, r, e, err)
+}
+
+// respondWithEvent responds with the event and its ETag unless the operation failed
+func (s *HTTPServer) respondWithEvent(w http.ResponseWriter, r *http.Request, e *models.Event, err error) {
+	if err != nil {
+		s.AppError(err, w, r)
+		return
+	}
+	w.Header().Set("ETag", etag(e.Version))
+	render.Respond(w, r, newEventDto(e))
+}
+
+func newIntervalDtos(intervals []models.Interval) []gen.Interval {
+	dtos := make([]gen.Interval, 0, len(intervals))
+	for _, i := range intervals {
+		dtos = append(dtos, gen.Interval{Start: i.Start, End: i.End})
+	}
+	return dtos
+}
+
+func (s *HTTPServer) GetFreeBusy(w http.ResponseWriter, r *http.Request, params gen.GetFreeBusyParams) {
+	var minFree time.Duration
+	if params.MinFree != nil {
+		minFree = time.Duration(*params.MinFree) * time.Second
+	}
+	freeBusy, err := s.app.GetFreeBusy(r.Context(), params.OwnerId, params.From, params.To, minFree)
+	if err != nil {
+		s.AppError(err, w, r)
+		return
+	}
+
+	result := gen.FreeBusy{Busy: make([]gen.OwnerBusy, 0, len(params.OwnerId)), Free: newIntervalDtos(freeBusy.Free)}
+	for _, owner := range params.OwnerId {
+		result.Busy = append(result.Busy, gen.OwnerBusy{OwnerId: owner, Intervals: newIntervalDtos(freeBusy.Busy[owner])})
+	}
+	render.Respond(w, r, result)
+}
+
+func (s *HTTPServer) FindSlots(w http.ResponseWriter, r *http.Request, params gen.FindSlotsParams) {
+	query := models.SlotQuery{
+		Attendees: params.Attendee,
+		Duration:  time.Duration(params.Duration) * time.Second,
+		From:      params.From,
+		To:        params.To,
+		Limit:     models.DefaultSlotLimit,
+	}
+	var workStart, workEnd, timezone string
+	var workDays []string
+	if params.WorkStart != nil {
+		workStart = *params.WorkStart
+	}
+	if params.WorkEnd != nil {
+		workEnd = *params.WorkEnd
+	}
+	if params.WorkDay != nil {
+		workDays = *params.WorkDay
+	}
+	if params.Timezone != nil {
+		timezone = *params.Timezone
+	}
+	if params.Limit != nil {
+		query.Limit = *params.Limit
+	}
+	if err := query.SetWorkingHours(workStart, workEnd, workDays, timezone); err != nil {
+		s.BadRequest(err, w, r)
+		return
+	}
+
+	slots, err := s.app.FindSlots(r.Context(), query)
+	if err != nil {
+		s.AppError(err, w, r)
+		return
+	}
+	render.Respond(w, r, newIntervalDtos(slots))
+}
+
+// agendaQuery - the agenda parameters shared by listing and exporting events
+type agendaQuery struct {
+	agenda    string
+	start     *time.Time
+	timezone  *string
+	weekStart *string
+	week      *string
+	owner     string
+}
+
+func (s *HTTPServer) getAgenda(ctx context.Context, q agendaQuery) ([]*models.Event, error) {
+	if q.start != nil && q.start.IsZero() {
+		// the generated binder sets optional time parameters to zero when they are missing
+		q.start = nil
+	}
+	var timezone string
+	if q.timezone != nil {
+		timezone = *q.timezone
+	}
+	if q.week != nil {
+		if q.agenda != "weekly" || q.start != nil || q.weekStart != nil {
+			return nil, fmt.Errorf("%w: http: week is only used by the weekly agenda instead of start_from "+
+				"and week_start", models.ErrValueError)
+		}
+		loc := time.UTC
+		if timezone != "" {
+			var err error
+			if loc, err = models.LoadTimezone(timezone); err != nil {
+				return nil, err
+			}
+		}
+		return s.app.GetISOWeekAgenda(ctx, *q.week, loc, q.owner)
+	}
+	if q.start == nil {
+		return nil, fmt.Errorf("%w: http: start_from is required along with the agenda", models.ErrValueError)
+	}
+	start, err := models.InTimezone(*q.start, timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	switch q.agenda {
 	case "daily":
-		events, err = s.app.GetDailyAgenda(r.Context(), params.StartFrom)
+		return s.app.GetDailyAgenda(ctx, start, q.owner)
 	case "weekly":
-		events, err = s.app.GetWeeklyAgenda(r.Context(), params.StartFrom)
+		weekStart := s.app.WeekStart()
+		if q.weekStart != nil {
+			if weekStart, err = models.ParseWeekday(*q.weekStart); err != nil {
+				return nil, err
+			}
+		}
+		return s.app.GetWeeklyAgenda(ctx, start, weekStart, q.owner)
 	case "monthly":
-		events, err = s.app.GetMonthlyAgenda(r.Context(), params.StartFrom)
+		return s.app.GetMonthlyAgenda(ctx, start, q.owner)
 	default:
-		err = fmt.Errorf("http: invalid agenda type %s", params.Agenda)
-		s.BadRequest(err, w, r)
+		return nil, fmt.Errorf("%w: http: invalid agenda type %s", models.ErrValueError, q.agenda)
+	}
+}
+
+func (s *HTTPServer) ListEvents(w http.ResponseWriter, r *http.Request, params gen.ListEventsParams) {
+	var owner string
+	if params.OwnerId != nil {
+		owner = *params.OwnerId
+	}
+	events, err := s.getAgenda(r.Context(), agendaQuery{
+		agenda:    string(params.Agenda),
+		start:     params.StartFrom,
+		timezone:  params.Timezone,
+		weekStart: params.WeekStart,
+		week:      params.Week,
+		owner:     owner,
+	})
+	if err != nil {
+		s.AppError(err, w, r)
 		return
 	}
 
+	result := make([]*gen.Event, 0, len(events))
+	for _, e := range events {
+		result = append(result, newEventDto(e))
+	}
+	render.Respond(w, r, result)
+}
+
+// ListEventRange lists a page of events of the date range
+func (s *HTTPServer) ListEventRange(w http.ResponseWriter, r *http.Request, params gen.ListEventRangeParams) {
+	var owner, pageToken string
+	var pageSize int
+	if params.OwnerId != nil {
+		owner = *params.OwnerId
+	}
+	if params.PageSize != nil {
+		pageSize = *params.PageSize
+	}
+	if params.PageToken != nil {
+		pageToken = *params.PageToken
+	}
+	page, err := s.app.ListEventRange(r.Context(), owner, params.From, params.To, pageSize, pageToken)
+	if err != nil {
+		s.AppError(err, w, r)
+		return
+	}
+
+	result := gen.EventPage{Events: make([]gen.Event, 0, len(page.Events))}
+	for _, e := range page.Events {
+		result.Events = append(result.Events, *newEventDto(e))
+	}
+	if page.NextToken != "" {
+		result.NextPageToken = &page.NextToken
+	}
+	render.Respond(w, r, result)
+}
+
+// SearchEvents finds events by the words of their titles and notes
+func (s *HTTPServer) SearchEvents(w http.ResponseWriter, r *http.Request, params gen.SearchEventsParams) {
+	query := models.SearchQuery{Text: params.Q}
+	if params.OwnerId != nil {
+		query.Owner = *params.OwnerId
+	}
+	if params.From != nil {
+		query.From = *params.From
+	}
+	if params.To != nil {
+		query.To = *params.To
+	}
+	if params.Limit != nil {
+		query.Limit = *params.Limit
+	}
+	events, err := s.app.SearchEvents(r.Context(), query)
 	if err != nil {
-		s.InternalError(err, w, r)
+		s.AppError(err, w, r)
 		return
 	}
 
 	result := make([]*gen.Event, 0, len(events))
 	for _, e := range events {
-		result = append(result, &gen.Event{
-			AlertBefore: int(e.AlertBefore.Seconds()),
-			EndsAt:      e.EndsAt,
-			Id:          e.ID,
-			Notes:       e.Notes,
-			OwnerId:     e.OwnerID,
-			StartsAt:    e.StartsAt,
-			Title:       e.Title,
+		result = append(result, newEventDto(e))
+	}
+	render.Respond(w, r, result)
+}
+
+// ExportEvents renders the agenda or all events of the owner as an iCalendar feed, the authenticated caller
+// must be able to edit events of the owner
+func (s *HTTPServer) ExportEvents(w http.ResponseWriter, r *http.Request, params gen.ExportEventsParams) {
+	var owner string
+	identity, authenticated := auth.FromContext(r.Context())
+	if params.OwnerId != nil {
+		owner = *params.OwnerId
+	} else if authenticated {
+		owner = identity.Subject
+	}
+	if authenticated && !identity.CanEdit(owner) {
+		err := fmt.Errorf("%w: %s may not export events of %s", models.ErrForbidden, identity.Subject, owner)
+		s.AppError(err, w, r)
+		return
+	}
+
+	var events []*models.Event
+	var err error
+	switch {
+	case params.Agenda != nil:
+		events, err = s.getAgenda(r.Context(), agendaQuery{
+			agenda:    string(*params.Agenda),
+			start:     params.StartFrom,
+			timezone:  params.Timezone,
+			weekStart: params.WeekStart,
+			week:      params.Week,
+			owner:     owner,
 		})
+	case owner == "":
+		err = fmt.Errorf("%w: http: owner_id is required to export all events", models.ErrValueError)
+	default:
+		events, err = s.app.GetOwnerEvents(r.Context(), owner)
+	}
+	if err != nil {
+		s.AppError(err, w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	if err = ical.Encode(w, events); err != nil {
+		s.log.Errorf("iCalendar export failed - %v", err)
+	}
+}
+
+// ImportEvents stores events of an iCalendar feed and reports the result of every entry
+func (s *HTTPServer) ImportEvents(w http.ResponseWriter, r *http.Request, params gen.ImportEventsParams) {
+	var owner string
+	if params.OwnerId != nil {
+		owner = *params.OwnerId
+	} else if identity, ok := auth.FromContext(r.Context()); ok {
+		owner = identity.Subject
+	}
+	if owner == "" {
+		s.BadRequest(fmt.Errorf("http: owner_id is required to import events"), w, r)
+		return
+	}
+
+	entries, err := ical.Decode(r.Body, owner)
+	if err != nil {
+		s.BadRequest(err, w, r)
+		return
+	}
+	s.app.ImportEvents(r.Context(), entries)
+
+	result := make([]gen.ImportResult, 0, len(entries))
+	for _, entry := range entries {
+		item := gen.ImportResult{Uid: entry.UID, Line: entry.Line, Imported: entry.Err == nil}
+		if entry.Err != nil {
+			msg := entry.Err.Error()
+			item.Error = &msg
+		} else {
+			item.Id = &entry.Event.ID
+		}
+		result = append(result, item)
 	}
 	render.Respond(w, r, result)
 }
 
-func (s *HTTPServer) DeleteEvent(w http.ResponseWriter, r *http.Request, id string) {
-	if e, err := s.app.GetEvent(r.Context(), id); errors.Is(err, models.ErrNotFound) {
-		s.NotFound(err, w, r)
+// DeleteEvent moves the event into the trash, with If-Match it is deleted only when the event has the version of it
+func (s *HTTPServer) DeleteEvent(w http.ResponseWriter, r *http.Request, id string, params gen.DeleteEventParams) {
+	version, err := ifMatchVersion(params.IfMatch)
+	if err != nil {
+		s.BadRequest(err, w, r)
 		return
-	} else if err != nil {
-		s.InternalError(err, w, r)
+	}
+	e, err := s.app.GetEvent(r.Context(), id)
+	switch {
+	case err != nil:
+		s.AppError(err, w, r)
+		return
+	case version != 0 && version != e.Version:
+		s.AppError(fmt.Errorf("%w: event %s is at version %d", models.ErrConflict, e.ID, e.Version), w, r)
 		return
-	} else if err = s.app.DeleteEvent(r.Context(), e); err != nil {
-		s.InternalError(err, w, r)
+	}
+	if err = s.app.DeleteEvent(r.Context(), e); err != nil {
+		s.AppError(err, w, r)
 		return
 	}
 	s.NoEror(w, r)
 }
 
 func (s *HTTPServer) GetEvent(w http.ResponseWriter, r *http.Request, id string) {
-	switch e, err := s.app.GetEvent(r.Context(), id); {
-	case errors.Is(err, models.ErrNotFound):
-		s.NotFound(err, w, r)
-		return
-	case err != nil:
-		s.InternalError(err, w, r)
+	e, err := s.app.GetEvent(r.Context(), id)
+	s.respondWithEvent(w, r, e, err)
+}
+
+// GetEventHistory lists the changes of the event oldest first
+func (s *HTTPServer) GetEventHistory(w http.ResponseWriter, r *http.Request, id string) {
+	records, err := s.app.GetEventHistory(r.Context(), id)
+	if err != nil {
+		s.AppError(err, w, r)
 		return
-	default:
-		render.Respond(w, r, &gen.Event{
-			AlertBefore: int(e.AlertBefore.Seconds()),
-			EndsAt:      e.EndsAt,
-			Id:          e.ID,
-			Notes:       e.Notes,
-			OwnerId:     e.OwnerID,
-			StartsAt:    e.StartsAt,
-			Title:       e.Title,
-		})
 	}
+
+	result := make([]gen.AuditRecord, 0, len(records))
+	for _, record := range records {
+		result = append(result, newAuditRecordDto(record))
+	}
+	render.Respond(w, r, result)
 }
 
-func (s *HTTPServer) PutEvent(w http.ResponseWriter, r *http.Request, id string) {
+// PutEvent replaces the event, with If-Match it is replaced only when the event has the version of it
+func (s *HTTPServer) PutEvent(w http.ResponseWriter, r *http.Request, id string, params gen.PutEventParams) {
+	version, err := ifMatchVersion(params.IfMatch)
+	if err != nil {
+		s.BadRequest(err, w, r)
+		return
+	}
 	eventDto := gen.Event{}
 	if err := render.Decode(r, &eventDto); err != nil {
 		s.BadRequest(err, w, r)
 		return
 	}
 
-	event, err := models.NewEvent(id, eventDto.Title, eventDto.StartsAt, eventDto.EndsAt, eventDto.OwnerId)
+	event, err := newEventModel(id, &eventDto)
+	if err != nil {
+		s.BadRequest(err, w, r)
+		return
+	}
+	event.Version = version
+	err = s.app.UpdateEvent(r.Context(), event, allowOverlap(params.AllowOverlap))
+	s.respondWithEvent(w, r, event, err)
+}
+
+// PatchEvent changes fields of the event with a JSON Merge Patch, with If-Match it is changed only
+// when the event has the version of it
+func (s *HTTPServer) PatchEvent(w http.ResponseWriter, r *http.Request, id string, params gen.PatchEventParams) {
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != mergePatchType {
+		s.UnsupportedMediaType(fmt.Errorf("http: a patch must be %s", mergePatchType), w, r)
+		return
+	}
+	version, err := ifMatchVersion(params.IfMatch)
 	if err != nil {
 		s.BadRequest(err, w, r)
 		return
 	}
-	event.AlertBefore = time.Duration(eventDto.AlertBefore * 1_000_000_000)
-	event.Notes = eventDto.Notes
-	if err = s.app.UpdateEvent(r.Context(), event); err != nil {
-		s.InternalError(err, w, r)
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.BadRequest(err, w, r)
 		return
 	}
-	render.Respond(w, r, &gen.Event{
-		AlertBefore: int(event.AlertBefore.Seconds()),
-		EndsAt:      event.EndsAt,
-		Id:          event.ID,
-		Notes:       event.Notes,
-		OwnerId:     event.OwnerID,
-		StartsAt:    event.StartsAt,
-		Title:       event.Title,
-	})
+
+	apply := func(event *models.Event) error {
+		return applyEventPatch(event, data)
+	}
+	event, err := s.app.PatchEvent(r.Context(), id, version, apply, allowOverlap(params.AllowOverlap))
+	s.respondWithEvent(w, r, event, err)
+}
+
+// ListTrash lists the deleted events of the owner
+func (s *HTTPServer) ListTrash(w http.ResponseWriter, r *http.Request, params gen.ListTrashParams) {
+	var owner string
+	if params.OwnerId != nil {
+		owner = *params.OwnerId
+	}
+	events, err := s.app.ListTrash(r.Context(), owner)
+	if err != nil {
+		s.AppError(err, w, r)
+		return
+	}
+
+	result := make([]*gen.Event, 0, len(events))
+	for _, e := range events {
+		result = append(result, newEventDto(e))
+	}
+	render.Respond(w, r, result)
+}
+
+// RestoreEvent brings the event back from the trash, with If-Match only when the deleted event has the version
+func (s *HTTPServer) RestoreEvent(w http.ResponseWriter, r *http.Request, id string, params gen.RestoreEventParams) {
+	version, err := ifMatchVersion(params.IfMatch)
+	if err != nil {
+		s.BadRequest(err, w, r)
+		return
+	}
+	e, err := s.app.RestoreEvent(r.Context(), id, version)
+	s.respondWithEvent(w, r, e, err)
 }
 
-func NewServer(host string, port string, logger common.Logger, app *app.App) *HTTPServer {
-	return &HTTPServer{host: host, port: port, app: app, log: logger}
+// PurgeEvent removes the event from the trash for good, with If-Match only when the deleted event has the version
+func (s *HTTPServer) PurgeEvent(w http.ResponseWriter, r *http.Request, id string, params gen.PurgeEventParams) {
+	version, err := ifMatchVersion(params.IfMatch)
+	if err != nil {
+		s.BadRequest(err, w, r)
+		return
+	}
+	if err = s.app.PurgeEvent(r.Context(), id, version); err != nil {
+		s.AppError(err, w, r)
+		return
+	}
+	s.NoEror(w, r)
+}
+
+// NewServer makes a server, requests are not authenticated when the verifier is nil
+func NewServer(host string, port string, logger common.Logger, app *app.App, verifier *auth.Verifier) *HTTPServer {
+	return &HTTPServer{host: host, port: port, app: app, log: logger, verifier: verifier}
 }
 
 func (s *HTTPServer) Start() error {
@@ -173,6 +637,9 @@ func (s *HTTPServer) Start() error {
 func (s *HTTPServer) buildRouter() *chi.Mux {
 	apiRouter := chi.NewRouter()
 	apiRouter.Use(middleware.LoggingMiddleware(s.log))
+	if s.verifier != nil {
+		apiRouter.Use(middleware.AuthMiddleware(s.verifier, s.log))
+	}
 	rootRouter := chi.NewRouter()
 	rootRouter.Mount("/api", gen.HandlerFromMux(s, apiRouter))
 	return rootRouter
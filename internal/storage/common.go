@@ -3,19 +3,39 @@ package storage
 import (
 	"fmt"
 
+	"github.com/VladNF/calendar/internal/common"
 	"github.com/VladNF/calendar/internal/models"
 	"github.com/VladNF/calendar/internal/storage/mem"
 	"github.com/VladNF/calendar/internal/storage/pgsql"
+	"github.com/VladNF/calendar/internal/storage/sqlite"
 )
 
-func NewStorage(storageType string) (models.EventsRepo, error) {
-	switch storageType {
+func NewStorage(conf common.StorageConf) (models.EventsRepo, error) {
+	switch conf.Kind {
 	case "in-memory":
-		return mem.NewMemoryStorage(), nil
+		return mem.NewMemoryStorage(conf.Path, conf.SnapshotInterval)
 	case "pgsql":
-		db, _ := pgsql.NewPgSQLConnection()
-		return pgsql.NewPgSQLStorage(db), nil
+		db, err := pgsql.NewPgSQLConnection(conf)
+		if err != nil {
+			return nil, err
+		}
+		return pgsql.NewPgSQLStorage(db, conf.QueryTimeout), nil
+	case "sqlite":
+		return sqlite.NewSQLiteStorage(conf.Path, conf.QueryTimeout)
 	default:
-		return nil, fmt.Errorf("unsupported storage type %v", storageType)
+		return nil, fmt.Errorf("unsupported storage type %v", conf.Kind)
 	}
 }
+
+// NewAuditStorage returns the audit log of the events storage, in-memory storage keeps the last
+// AuditCapacity records in a ring buffer which is lost on restart, sql storages keep them in their database
+func NewAuditStorage(conf common.StorageConf, eventsRepo models.EventsRepo) (models.AuditRepo, error) {
+	if conf.Kind == "in-memory" {
+		return mem.NewAuditLog(conf.AuditCapacity), nil
+	}
+	audit, ok := eventsRepo.(models.AuditRepo)
+	if !ok {
+		return nil, fmt.Errorf("storage type %v keeps no audit log", conf.Kind)
+	}
+	return audit, nil
+}
@@ -0,0 +1,69 @@
+package mem
+
+import (
+	"context"
+	"sync"
+
+	"github.com/VladNF/calendar/internal/models"
+)
+
+// DefaultAuditCapacity is the number of records the audit log keeps unless another capacity is chosen
+const DefaultAuditCapacity = 10000
+
+// AuditLog - ring buffer of the last audit records, the oldest record is overwritten when it is full
+type AuditLog struct {
+	sync.RWMutex
+	records []models.AuditRecord
+	// next is the index the next record is written at, the oldest record is there once the log is full
+	next int
+	full bool
+}
+
+// Append keeps copies of the record and of its events, events of the record may change later
+func (l *AuditLog) Append(ctx context.Context, r *models.AuditRecord) error {
+	l.Lock()
+	defer l.Unlock()
+	record := *r
+	record.Before, record.After = copyEvent(r.Before), copyEvent(r.After)
+	l.records[l.next] = record
+	if l.next++; l.next == len(l.records) {
+		l.next, l.full = 0, true
+	}
+	return nil
+}
+
+// History returns the records of the event which are still in the log, oldest first
+func (l *AuditLog) History(ctx context.Context, eventID string) ([]*models.AuditRecord, error) {
+	l.RLock()
+	defer l.RUnlock()
+	oldest, count := 0, l.next
+	if l.full {
+		oldest, count = l.next, len(l.records)
+	}
+	r := []*models.AuditRecord{}
+	for i := 0; i < count; i++ {
+		if record := l.records[(oldest+i)%len(l.records)]; record.EventID == eventID {
+			record.Before, record.After = copyEvent(record.Before), copyEvent(record.After)
+			r = append(r, &record)
+		}
+	}
+	return r, nil
+}
+
+// copyEvent copies the event, attendees are copied on write so the copy may share them
+func copyEvent(e *models.Event) *models.Event {
+	if e == nil {
+		return nil
+	}
+	c := *e
+	return &c
+}
+
+// NewAuditLog returns an audit log keeping the last capacity records in memory, a capacity which
+// is not positive takes DefaultAuditCapacity
+func NewAuditLog(capacity int) *AuditLog {
+	if capacity <= 0 {
+		capacity = DefaultAuditCapacity
+	}
+	return &AuditLog{records: make([]models.AuditRecord, capacity)}
+}
@@ -1,6 +1,7 @@
 package mem
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"sync"
@@ -15,28 +16,104 @@ type MemoryStorage struct {
 	sync.RWMutex
 	eventFromID  EventList
 	eventFromDay map[string]EventList
+	recurring    EventList
+	// eventFromTerm is the inverted index of the words of titles and notes
+	eventFromTerm map[string]EventList
+	// trash keeps tombstones of the deleted events, they are not indexed
+	trash EventList
+	// persistence is nil unless the storage is kept in a file
+	persistence *persistence
 }
 
 func isoDate(t time.Time) string {
 	return t.Format("2006-01-02")
 }
 
-func joinEventLists(eventLists ...EventList) []*models.Event {
-	size := 0
-	for _, l := range eventLists {
-		size += len(l)
+// coveredDays returns UTC dates of the days [start, end) touches, a zero length span touches its start date
+func coveredDays(start, end time.Time) []string {
+	last := end.UTC()
+	if end.After(start) {
+		last = last.Add(-time.Nanosecond)
 	}
-	r := make([]*models.Event, 0, size)
-	for _, l := range eventLists {
-		for _, e := range l {
-			r = append(r, e)
+	var days []string
+	for day, _ := models.DayWindow(start.UTC()); !day.After(last); day = day.AddDate(0, 0, 1) {
+		days = append(days, isoDate(day))
+	}
+	return days
+}
+
+// candidates returns events which may overlap [lBound, uBound), the day index is keyed by UTC dates
+// so the window is checked both as is and as floating time for all-day events,
+// an empty owner matches events of any owner
+func (s *MemoryStorage) candidates(owner string, lBound, uBound time.Time) EventList {
+	from, to := lBound, uBound
+	if models.Floating(lBound).Before(from) {
+		from = models.Floating(lBound)
+	}
+	if models.Floating(uBound).After(to) {
+		to = models.Floating(uBound)
+	}
+
+	found := make(EventList)
+	for _, day := range coveredDays(from, to) {
+		for id, e := range s.eventFromDay[day] {
+			if owner == "" || e.OwnerID == owner {
+				found[id] = e
+			}
 		}
 	}
+	for id, e := range s.recurring {
+		if owner == "" || e.OwnerID == owner {
+			found[id] = e
+		}
+	}
+	return found
+}
+
+// listEvents returns events and occurrences of the recurring events overlapping [lBound, uBound)
+func (s *MemoryStorage) listEvents(owner string, lBound, uBound time.Time) []*models.Event {
+	s.RLock()
+	defer s.RUnlock()
+	var r []*models.Event
+	for _, e := range s.candidates(owner, lBound, uBound) {
+		r = append(r, e.Occurrences(lBound, uBound)...)
+	}
 	sort.Slice(r, func(i, j int) bool { return r[i].StartsAt.Before(r[j].StartsAt) })
 	return r
 }
 
-func (s *MemoryStorage) Get(id string) (*models.Event, error) {
+func (s *MemoryStorage) index(e *models.Event) {
+	for _, term := range e.Terms() {
+		if _, ok := s.eventFromTerm[term]; !ok {
+			s.eventFromTerm[term] = make(EventList)
+		}
+		s.eventFromTerm[term][e.ID] = e
+	}
+	if e.IsRecurring() {
+		s.recurring[e.ID] = e
+		return
+	}
+	for _, day := range coveredDays(e.StartsAt, e.EndsAt) {
+		if _, ok := s.eventFromDay[day]; !ok {
+			s.eventFromDay[day] = make(EventList)
+		}
+		s.eventFromDay[day][e.ID] = e
+	}
+}
+
+func (s *MemoryStorage) unindex(e *models.Event) {
+	for _, term := range e.Terms() {
+		if delete(s.eventFromTerm[term], e.ID); len(s.eventFromTerm[term]) == 0 {
+			delete(s.eventFromTerm, term)
+		}
+	}
+	delete(s.recurring, e.ID)
+	for _, day := range coveredDays(e.StartsAt, e.EndsAt) {
+		delete(s.eventFromDay[day], e.ID)
+	}
+}
+
+func (s *MemoryStorage) Get(ctx context.Context, id string) (*models.Event, error) {
 	s.RLock()
 	defer s.RUnlock()
 	if e, ok := s.eventFromID[id]; ok {
@@ -46,67 +123,299 @@ func (s *MemoryStorage) Get(id string) (*models.Event, error) {
 	return nil, models.ErrNotFound
 }
 
-func (s *MemoryStorage) Put(e *models.Event) error {
+// Create stores a new event, it fails with ErrAlreadyExists when an event has its ID
+func (s *MemoryStorage) Create(ctx context.Context, e *models.Event) error {
 	s.Lock()
 	defer s.Unlock()
-	s.eventFromID[e.ID] = e
-	if _, ok := s.eventFromDay[isoDate(e.StartsAt)]; !ok {
-		s.eventFromDay[isoDate(e.StartsAt)] = make(EventList)
+	if _, ok := s.eventFromID[e.ID]; ok {
+		return fmt.Errorf("%w: event %s", models.ErrAlreadyExists, e.ID)
+	} else if _, ok = s.trash[e.ID]; ok {
+		return fmt.Errorf("%w: event %s is in the trash", models.ErrAlreadyExists, e.ID)
 	}
-	s.eventFromDay[isoDate(e.StartsAt)][e.ID] = e
-	return nil
+	return s.store(e, 1)
 }
 
-func (s *MemoryStorage) Delete(e *models.Event) error {
+// Update replaces the stored event when the version of the event is the stored one
+func (s *MemoryStorage) Update(ctx context.Context, e *models.Event) error {
 	s.Lock()
 	defer s.Unlock()
-	delete(s.eventFromID, e.ID)
-	delete(s.eventFromDay[isoDate(e.StartsAt)], e.ID)
+	prev, ok := s.eventFromID[e.ID]
+	switch {
+	case !ok:
+		return models.ErrNotFound
+	case e.Version != prev.Version:
+		return fmt.Errorf("%w: event %s is not at version %d", models.ErrConflict, e.ID, e.Version)
+	}
+	return s.store(e, e.Version+1)
+}
+
+// store logs the event with the new version before it is put into the storage
+func (s *MemoryStorage) store(e *models.Event, version int64) error {
+	updatedAt := time.Now().UTC()
+	if s.persistence != nil {
+		fe := models.NewEventDocument(e)
+		fe.Version, fe.UpdatedAt = version, updatedAt
+		if err := s.persistence.append(logEntry{Op: opPut, Event: fe}); err != nil {
+			return fmt.Errorf("%w: put failed -> %v", models.ErrDataError, err)
+		}
+		defer s.persistence.compact(s)
+	}
+	e.Version, e.UpdatedAt = version, updatedAt
+	s.put(e)
 	return nil
 }
 
-func (s *MemoryStorage) GetDayList(d time.Time) ([]*models.Event, error) {
+// put replaces the event, an event having DeletedAt replaces it in the trash
+func (s *MemoryStorage) put(e *models.Event) {
+	s.delete(e.ID)
+	if e.IsDeleted() {
+		s.trash[e.ID] = e
+		return
+	}
+	s.eventFromID[e.ID] = e
+	s.index(e)
+}
+
+// Delete moves the event into the trash when its version is the stored one, a zero version deletes any
+func (s *MemoryStorage) Delete(ctx context.Context, e *models.Event) error {
+	s.Lock()
+	defer s.Unlock()
+	prev, ok := s.eventFromID[e.ID]
+	switch {
+	case !ok:
+		return models.ErrNotFound
+	case e.Version != 0 && e.Version != prev.Version:
+		return fmt.Errorf("%w: event %s is not at version %d", models.ErrConflict, e.ID, e.Version)
+	}
+	deleted := *prev
+	deleted.DeletedAt = time.Now().UTC()
+	return s.store(&deleted, prev.Version+1)
+}
+
+// GetDeleted returns the event from the trash
+func (s *MemoryStorage) GetDeleted(ctx context.Context, id string) (*models.Event, error) {
 	s.RLock()
 	defer s.RUnlock()
-	return joinEventLists(s.eventFromDay[isoDate(d)]), nil
+	if e, ok := s.trash[id]; ok {
+		return e, nil
+	}
+	return nil, models.ErrNotFound
 }
 
-func (s *MemoryStorage) GetWeekList(d time.Time) ([]*models.Event, error) {
+// ListDeleted returns the events in the trash, the last deleted go first, an empty owner lists them all
+func (s *MemoryStorage) ListDeleted(ctx context.Context, owner string) ([]*models.Event, error) {
 	s.RLock()
 	defer s.RUnlock()
-	dayLists := make([]EventList, 0, 7)
-	sunday := d.AddDate(0, 0, int(time.Sunday-d.Weekday()))
-	for i := 0; i < 7; i++ {
-		dayLists = append(dayLists, s.eventFromDay[isoDate(sunday.AddDate(0, 0, i))])
+	var r []*models.Event
+	for _, e := range s.trash {
+		if owner == "" || e.OwnerID == owner {
+			r = append(r, e)
+		}
+	}
+	models.SortDeleted(r)
+	return r, nil
+}
+
+// Restore brings the event back from the trash when its version is the stored one, a zero version
+// restores any
+func (s *MemoryStorage) Restore(ctx context.Context, e *models.Event) error {
+	s.Lock()
+	defer s.Unlock()
+	prev, ok := s.trash[e.ID]
+	switch {
+	case !ok:
+		return models.ErrNotFound
+	case e.Version != 0 && e.Version != prev.Version:
+		return fmt.Errorf("%w: event %s is not at version %d", models.ErrConflict, e.ID, e.Version)
+	}
+	restored := *prev
+	restored.DeletedAt = time.Time{}
+	return s.store(&restored, prev.Version+1)
+}
+
+// Purge removes the event from the trash for good when its version is the stored one, a zero version
+// purges any
+func (s *MemoryStorage) Purge(ctx context.Context, e *models.Event) error {
+	s.Lock()
+	defer s.Unlock()
+	prev, ok := s.trash[e.ID]
+	switch {
+	case !ok:
+		return models.ErrNotFound
+	case e.Version != 0 && e.Version != prev.Version:
+		return fmt.Errorf("%w: event %s is not at version %d", models.ErrConflict, e.ID, e.Version)
 	}
-	return joinEventLists(dayLists...), nil
+	return s.purge([]string{e.ID})
 }
 
-func (s *MemoryStorage) GetMonthList(d time.Time) ([]*models.Event, error) {
+// PurgeDeleted removes the events deleted before the time from the trash for good
+func (s *MemoryStorage) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	s.Lock()
+	defer s.Unlock()
+	var ids []string
+	for id, e := range s.trash {
+		if e.DeletedAt.Before(before) {
+			ids = append(ids, id)
+		}
+	}
+	if err := s.purge(ids); err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// purge logs the removals before the events are removed
+func (s *MemoryStorage) purge(ids []string) error {
+	if s.persistence != nil && len(ids) > 0 {
+		for _, id := range ids {
+			if err := s.persistence.append(logEntry{Op: opDelete, ID: id}); err != nil {
+				return fmt.Errorf("%w: purge failed -> %v", models.ErrDataError, err)
+			}
+		}
+		defer s.persistence.compact(s)
+	}
+	for _, id := range ids {
+		s.delete(id)
+	}
+	return nil
+}
+
+func (s *MemoryStorage) delete(id string) {
+	if prev, ok := s.eventFromID[id]; ok {
+		s.unindex(prev)
+	}
+	delete(s.eventFromID, id)
+	delete(s.trash, id)
+}
+
+func (s *MemoryStorage) GetDayList(ctx context.Context, d time.Time) ([]*models.Event, error) {
+	lBound, uBound := models.DayWindow(d)
+	return s.listEvents("", lBound, uBound), nil
+}
+
+func (s *MemoryStorage) GetWeekList(ctx context.Context, d time.Time, weekStart time.Weekday) ([]*models.Event, error) {
+	lBound, uBound := models.WeekWindow(d, weekStart)
+	return s.listEvents("", lBound, uBound), nil
+}
+
+func (s *MemoryStorage) GetMonthList(ctx context.Context, d time.Time) ([]*models.Event, error) {
+	lBound, uBound := models.MonthWindow(d)
+	return s.listEvents("", lBound, uBound), nil
+}
+
+func (s *MemoryStorage) IsBusy(ctx context.Context, d1, d2 time.Time) (bool, error) {
+	return s.isBusy("", d1, d2, "")
+}
+
+func (s *MemoryStorage) GetDayListByOwner(ctx context.Context, owner string, d time.Time) ([]*models.Event, error) {
+	lBound, uBound := models.DayWindow(d)
+	return s.listEvents(owner, lBound, uBound), nil
+}
+
+func (s *MemoryStorage) GetWeekListByOwner(
+	ctx context.Context, owner string, d time.Time, weekStart time.Weekday,
+) ([]*models.Event, error) {
+	lBound, uBound := models.WeekWindow(d, weekStart)
+	return s.listEvents(owner, lBound, uBound), nil
+}
+
+func (s *MemoryStorage) GetMonthListByOwner(ctx context.Context, owner string, d time.Time) ([]*models.Event, error) {
+	lBound, uBound := models.MonthWindow(d)
+	return s.listEvents(owner, lBound, uBound), nil
+}
+
+func (s *MemoryStorage) IsOwnerBusy(
+	ctx context.Context, owner string, d1, d2 time.Time, exceptID string,
+) (bool, error) {
+	return s.isBusy(owner, d1, d2, exceptID)
+}
+
+// GetRangeListByOwners returns events of the owners and occurrences of their recurring events
+// overlapping [lBound, uBound)
+func (s *MemoryStorage) GetRangeListByOwners(
+	ctx context.Context, owners []string, lBound, uBound time.Time,
+) ([]*models.Event, error) {
+	var r []*models.Event
+	listed := make(map[string]bool, len(owners))
+	for _, owner := range owners {
+		// every owner is listed once and an empty one matches no events the way sql storages match them
+		if listed[owner] || owner == "" {
+			continue
+		}
+		listed[owner] = true
+		r = append(r, s.listEvents(owner, lBound, uBound)...)
+	}
+	sort.SliceStable(r, func(i, j int) bool { return r[i].StartsAt.Before(r[j].StartsAt) })
+	return r, nil
+}
+
+// GetRangePage returns up to size events and occurrences of recurring events overlapping [lBound, uBound)
+// which follow the cursor, an empty owner lists events of all owners
+func (s *MemoryStorage) GetRangePage(
+	ctx context.Context, owner string, lBound, uBound time.Time, after *models.Cursor, size int,
+) (models.Page, error) {
+	return models.Paginate(s.listEvents(owner, lBound, uBound), after, size), nil
+}
+
+// Search returns events having all the words of the search text ordered by start,
+// recurring events are expanded into occurrences when the search has a date range
+func (s *MemoryStorage) Search(ctx context.Context, q models.SearchQuery) ([]*models.Event, error) {
+	terms := models.SearchTerms(q.Text)
+	if len(terms) == 0 {
+		return nil, nil
+	}
 	s.RLock()
 	defer s.RUnlock()
-	dayLists := make([]EventList, 0, 31)
-	y, m, _ := d.Date()
-	day := time.Date(y, m, 1, 0, 0, 0, 0, d.Location())
-	for day.Month() == m {
-		dayLists = append(dayLists, s.eventFromDay[isoDate(day)])
-		day = day.AddDate(0, 0, 1)
+	var r []*models.Event
+	for id, e := range s.eventFromTerm[terms[0]] {
+		if q.Owner != "" && e.OwnerID != q.Owner || !s.hasTerms(id, terms[1:]) {
+			continue
+		}
+		if q.HasRange() {
+			r = append(r, e.Occurrences(q.From, q.To)...)
+		} else {
+			r = append(r, e)
+		}
 	}
-	return joinEventLists(dayLists...), nil
+	models.SortListing(r)
+	if len(r) > q.Limit {
+		r = r[:q.Limit]
+	}
+	return r, nil
 }
 
-func (s *MemoryStorage) IsBusy(d1, d2 time.Time) (bool, error) {
-	if !models.FitsOneDay(d1, d1) {
-		return false, fmt.Errorf("%w: start and end must be of the same date", models.ErrValueError)
+func (s *MemoryStorage) hasTerms(id string, terms []string) bool {
+	for _, term := range terms {
+		if _, ok := s.eventFromTerm[term][id]; !ok {
+			return false
+		}
 	}
+	return true
+}
+
+// GetListByOwner returns all events of the owner, recurring events are not expanded
+func (s *MemoryStorage) GetListByOwner(ctx context.Context, owner string) ([]*models.Event, error) {
 	s.RLock()
 	defer s.RUnlock()
-	events, err := s.GetDayList(d1)
-	if err != nil {
-		return false, err
+	var r []*models.Event
+	for _, e := range s.eventFromID {
+		if e.OwnerID == owner {
+			r = append(r, e)
+		}
+	}
+	sort.Slice(r, func(i, j int) bool { return r[i].StartsAt.Before(r[j].StartsAt) })
+	return r, nil
+}
+
+// isBusy checks overlaps with timed events, all-day events do not make the time busy
+func (s *MemoryStorage) isBusy(owner string, d1, d2 time.Time, exceptID string) (bool, error) {
+	if !d1.Before(d2) {
+		return false, fmt.Errorf("%w: start must be before end", models.ErrValueError)
 	}
-	for _, e := range events {
-		if (e.StartsAt.After(d1) && e.StartsAt.Before(d2)) || (e.EndsAt.After(d1) && e.EndsAt.Before(d2)) {
+	s.RLock()
+	defer s.RUnlock()
+	for _, e := range s.candidates(owner, d1, d2) {
+		if !e.AllDay && e.ID != exceptID && e.Overlaps(d1, d2) {
 			return true, nil
 		}
 	}
@@ -114,9 +423,23 @@ func (s *MemoryStorage) IsBusy(d1, d2 time.Time) (bool, error) {
 	return false, nil
 }
 
-func NewMemoryStorage() models.EventsRepo {
-	return &MemoryStorage{
-		eventFromID:  make(EventList),
-		eventFromDay: make(map[string]EventList),
+// NewMemoryStorage returns a storage which is lost on restart when path is empty, otherwise it replays
+// the snapshot at path and the write log at path.log, snapshots are taken on writes once snapshotInterval
+// has passed since the previous one and zero takes them only at startup
+func NewMemoryStorage(path string, snapshotInterval time.Duration) (models.EventsRepo, error) {
+	s := &MemoryStorage{
+		eventFromID:   make(EventList),
+		eventFromDay:  make(map[string]EventList),
+		recurring:     make(EventList),
+		eventFromTerm: make(map[string]EventList),
+		trash:         make(EventList),
+	}
+	if path == "" {
+		return s, nil
+	}
+	s.persistence = &persistence{path: path, interval: snapshotInterval}
+	if err := s.persistence.open(s); err != nil {
+		return nil, fmt.Errorf("in-memory storage %s was not loaded -> %w", path, err)
 	}
+	return s, nil
 }
@@ -0,0 +1,177 @@
+package mem
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/VladNF/calendar/internal/models"
+)
+
+const (
+	opPut    = "put"
+	opDelete = "delete"
+)
+
+// logEntry - a write of the log, Event is set for puts and ID for deletes, events are put into the trash
+// and deleted when they are purged
+type logEntry struct {
+	Op    string                `json:"op"`
+	Event *models.EventDocument `json:"event,omitempty"`
+	ID    string                `json:"id,omitempty"`
+}
+
+// persistence keeps the storage in the snapshot file at path and the log of the writes made after it
+// at path.log, the log is truncated whenever a snapshot is taken
+type persistence struct {
+	path     string
+	log      *os.File
+	interval time.Duration
+	takenAt  time.Time
+}
+
+func (p *persistence) logPath() string {
+	return p.path + ".log"
+}
+
+// load reads the snapshot and replays the log on top of it
+func (p *persistence) load(s *MemoryStorage) error {
+	f, err := os.Open(p.path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return err
+	default:
+		var events []*models.EventDocument
+		err = json.NewDecoder(f).Decode(&events)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("snapshot %s is malformed -> %w", p.path, err)
+		}
+		for _, fe := range events {
+			e, err := fe.Event()
+			if err != nil {
+				return fmt.Errorf("snapshot %s is malformed -> %w", p.path, err)
+			}
+			s.put(e)
+		}
+	}
+
+	f, err = os.Open(p.logPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+	reader := bufio.NewReader(f)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// entries end with a newline, so a last line without it is a write cut short by a crash
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err = p.replay(s, data); err != nil {
+			return fmt.Errorf("write log %s is malformed at line %d -> %w", p.logPath(), line, err)
+		}
+	}
+}
+
+func (p *persistence) replay(s *MemoryStorage, data []byte) error {
+	var entry logEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return err
+	}
+	switch {
+	case entry.Op == opPut && entry.Event != nil:
+		e, err := entry.Event.Event()
+		if err != nil {
+			return err
+		}
+		s.put(e)
+	case entry.Op == opDelete:
+		s.delete(entry.ID)
+	default:
+		return fmt.Errorf("unexpected entry %q", entry.Op)
+	}
+	return nil
+}
+
+// append writes the entry to the log and flushes it to the disk
+func (p *persistence) append(entry logEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err = p.log.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return p.log.Sync()
+}
+
+// compact takes a snapshot of the storage when the interval has passed since the previous one,
+// the writes are in the log already so a failed snapshot is only retried on the next write
+func (p *persistence) compact(s *MemoryStorage) {
+	if p.interval > 0 && time.Since(p.takenAt) >= p.interval {
+		_ = p.snapshot(s)
+	}
+}
+
+// snapshot writes all the events to a temporary file which replaces the snapshot and then truncates the log,
+// a crash before the truncation leaves writes in the log which are replayed again harmlessly
+func (p *persistence) snapshot(s *MemoryStorage) error {
+	events := make([]*models.EventDocument, 0, len(s.eventFromID)+len(s.trash))
+	for _, e := range s.eventFromID {
+		events = append(events, models.NewEventDocument(e))
+	}
+	for _, e := range s.trash {
+		events = append(events, models.NewEventDocument(e))
+	}
+	data, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+	tmp := p.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, p.path); err != nil {
+		return err
+	}
+	if err = p.log.Truncate(0); err != nil {
+		return err
+	}
+	if _, err = p.log.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	p.takenAt = time.Now()
+	return nil
+}
+
+// open loads the storage from the files and compacts them into a fresh snapshot
+func (p *persistence) open(s *MemoryStorage) error {
+	if err := p.load(s); err != nil {
+		return err
+	}
+	var err error
+	if p.log, err = os.OpenFile(p.logPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600); err != nil {
+		return err
+	}
+	return p.snapshot(s)
+}
@@ -0,0 +1,94 @@
+package pgsql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/VladNF/calendar/internal/common"
+	"github.com/jmoiron/sqlx"
+)
+
+var ErrUnreachable = errors.New("storage is unreachable")
+
+const (
+	defaultConnectBackoff = time.Second
+	maxConnectBackoff     = 30 * time.Second
+)
+
+// DSN returns conf.DSN when it is set, otherwise a postgres URL made of the connection settings
+func DSN(conf common.StorageConf) string {
+	if conf.DSN != "" {
+		return conf.DSN
+	}
+	u := url.URL{Scheme: "postgres", Host: conf.Host, Path: "/" + conf.Database}
+	if conf.Port != "" {
+		u.Host = net.JoinHostPort(conf.Host, conf.Port)
+	}
+	if conf.User != "" {
+		u.User = url.UserPassword(conf.User, conf.Password)
+	}
+	if conf.SSLMode != "" {
+		u.RawQuery = url.Values{"sslmode": {conf.SSLMode}}.Encode()
+	}
+	return u.String()
+}
+
+// NewPgSQLConnection opens the database with the pool settings of conf and pings it until it responds,
+// it fails with ErrUnreachable when retries are exhausted and with ErrSchemaVersion when conf.VerifySchema
+// is set unless all the migrations are applied
+func NewPgSQLConnection(conf common.StorageConf) (*sqlx.DB, error) {
+	dsn := DSN(conf)
+	db, err := sqlx.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s -> %v", ErrUnreachable, redacted(dsn), err)
+	}
+	db.SetMaxOpenConns(conf.MaxOpenConns)
+	db.SetMaxIdleConns(conf.MaxIdleConns)
+	db.SetConnMaxLifetime(conf.ConnMaxLifetime)
+
+	if err = ping(db, conf); err != nil {
+		db.Close()
+		attempts := conf.ConnectRetries + 1
+		return nil, fmt.Errorf("%w: %s after %d attempts -> %v", ErrUnreachable, redacted(dsn), attempts, err)
+	}
+	if !conf.VerifySchema {
+		return db, nil
+	}
+	if err = VerifySchema(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// ping tries conf.ConnectRetries more times after the first failure doubling the backoff each time
+func ping(db *sqlx.DB, conf common.StorageConf) error {
+	backoff := conf.ConnectBackoff
+	if backoff <= 0 {
+		backoff = defaultConnectBackoff
+	}
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := withTimeout(context.Background(), conf.QueryTimeout)
+		err := db.PingContext(ctx)
+		cancel()
+		if err == nil || attempt >= conf.ConnectRetries {
+			return err
+		}
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > maxConnectBackoff {
+			backoff = maxConnectBackoff
+		}
+	}
+}
+
+// redacted hides the password of the DSN in errors
+func redacted(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil {
+		return u.Redacted()
+	}
+	return "database"
+}
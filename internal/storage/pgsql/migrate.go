@@ -0,0 +1,241 @@
+package pgsql
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var ErrSchemaVersion = errors.New("unexpected schema version")
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migration - a versioned schema change read from migrations/<version>_<name>.up.sql
+// and the matching .down.sql file which reverts it
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationState - a migration and the time it was applied at, AppliedAt is nil for pending ones
+type MigrationState struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Migrations returns the embedded migrations ordered by version, versions must start at 1
+// without gaps and every migration needs both files
+func Migrations() ([]Migration, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		name := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("migration %s is neither up nor down", name)
+		}
+		parts := strings.SplitN(strings.TrimSuffix(name, "."+direction+".sql"), "_", 2)
+		version, err := strconv.Atoi(parts[0])
+		if err != nil || len(parts) != 2 {
+			return nil, fmt.Errorf("migration %s is not named like 001_name.%s.sql", name, direction)
+		}
+		body, err := migrationFiles.ReadFile(path.Join("migrations", name))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: parts[1]}
+			byVersion[version] = m
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	for i, m := range migrations {
+		switch {
+		case m.Version != i+1:
+			return nil, fmt.Errorf("migration %d is missing", i+1)
+		case m.Up == "" || m.Down == "":
+			return nil, fmt.Errorf("migration %03d_%s needs both up and down files", m.Version, m.Name)
+		}
+	}
+	return migrations, nil
+}
+
+// LatestVersion returns the schema version the storage expects
+func LatestVersion() (int, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return 0, err
+	}
+	return len(migrations), nil
+}
+
+func ensureMigrationsTable(db *sqlx.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    integer primary key,
+		name       text not null,
+		applied_at timestamp with time zone not null default now()
+	)`)
+	return err
+}
+
+// SchemaVersion returns the version of the latest applied migration, zero when none is applied
+func SchemaVersion(db *sqlx.DB) (int, error) {
+	if err := ensureMigrationsTable(db); err != nil {
+		return 0, fmt.Errorf("schema version failed -> %w", err)
+	}
+	return appliedVersion(db)
+}
+
+func appliedVersion(db *sqlx.DB) (int, error) {
+	var version int
+	if err := db.Get(&version, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations"); err != nil {
+		return 0, fmt.Errorf("schema version failed -> %w", err)
+	}
+	return version, nil
+}
+
+// VerifySchema fails with ErrSchemaVersion unless all the migrations are applied, it only reads
+// the database and fails as well when no migration was ever applied
+func VerifySchema(db *sqlx.DB) error {
+	latest, err := LatestVersion()
+	if err != nil {
+		return err
+	}
+	var exists bool
+	if err = db.Get(&exists, "SELECT to_regclass('schema_migrations') IS NOT NULL"); err != nil {
+		return fmt.Errorf("schema version failed -> %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("%w: no migrations table, run calendar migrate up", ErrSchemaVersion)
+	}
+	version, err := appliedVersion(db)
+	if err != nil {
+		return err
+	}
+	if version != latest {
+		return fmt.Errorf("%w: %d, %d is expected, run calendar migrate up", ErrSchemaVersion, version, latest)
+	}
+	return nil
+}
+
+// MigrationStatus lists all the migrations along with the time they were applied at
+func MigrationStatus(db *sqlx.DB) ([]MigrationState, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	if err = ensureMigrationsTable(db); err != nil {
+		return nil, fmt.Errorf("migration status failed -> %w", err)
+	}
+	var applied []struct {
+		Version   int       `db:"version"`
+		AppliedAt time.Time `db:"applied_at"`
+	}
+	if err = db.Select(&applied, "SELECT version, applied_at FROM schema_migrations"); err != nil {
+		return nil, fmt.Errorf("migration status failed -> %w", err)
+	}
+	appliedAt := make(map[int]time.Time, len(applied))
+	for _, a := range applied {
+		appliedAt[a.Version] = a.AppliedAt
+	}
+
+	states := make([]MigrationState, 0, len(migrations))
+	for _, m := range migrations {
+		state := MigrationState{Migration: m}
+		if t, ok := appliedAt[m.Version]; ok {
+			state.AppliedAt = &t
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// MigrateUp applies the pending migrations one transaction each and returns the applied ones
+func MigrateUp(db *sqlx.DB) ([]Migration, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	version, err := SchemaVersion(db)
+	if err != nil {
+		return nil, err
+	}
+	if version > len(migrations) {
+		return nil, fmt.Errorf("%w: %d is newer than the migrations of this build", ErrSchemaVersion, version)
+	}
+	var applied []Migration
+	record := "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)"
+	for _, m := range migrations[version:] {
+		if err = inTransaction(db, m.Up, record, m.Version, m.Name); err != nil {
+			return applied, fmt.Errorf("migration %03d_%s failed -> %w", m.Version, m.Name, err)
+		}
+		applied = append(applied, m)
+	}
+	return applied, nil
+}
+
+// MigrateDown reverts the latest applied migration, it returns nil when none is applied
+func MigrateDown(db *sqlx.DB) (*Migration, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	version, err := SchemaVersion(db)
+	if err != nil || version == 0 {
+		return nil, err
+	}
+	if version > len(migrations) {
+		return nil, fmt.Errorf("%w: %d is newer than the migrations of this build", ErrSchemaVersion, version)
+	}
+	m := migrations[version-1]
+	if err = inTransaction(db, m.Down, "DELETE FROM schema_migrations WHERE version = $1", m.Version); err != nil {
+		return nil, fmt.Errorf("migration %03d_%s failed -> %w", m.Version, m.Name, err)
+	}
+	return &m, nil
+}
+
+// inTransaction runs the migration script and records the change of the version atomically
+func inTransaction(db *sqlx.DB, script string, record string, args ...interface{}) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck // it fails after commit only
+	if _, err = tx.Exec(script); err != nil {
+		return err
+	}
+	if _, err = tx.Exec(record, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
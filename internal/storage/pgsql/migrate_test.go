@@ -0,0 +1,28 @@
+package pgsql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrations(t *testing.T) {
+	migrations, err := Migrations()
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+	for i, m := range migrations {
+		require.Equal(t, i+1, m.Version)
+		require.NotEmpty(t, m.Name)
+		require.NotEmpty(t, m.Up)
+		require.NotEmpty(t, m.Down)
+	}
+
+	// tables adopted from the former sql/schema.sql get the columns added later
+	for _, column := range []string{"rrule", "exdates", "all_day", "timezone", "status"} {
+		require.Contains(t, migrations[0].Up, "add column if not exists "+column+" ")
+	}
+
+	latest, err := LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, len(migrations), latest)
+}
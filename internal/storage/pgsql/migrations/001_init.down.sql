@@ -0,0 +1,2 @@
+drop table if exists attendees;
+drop table if exists events;
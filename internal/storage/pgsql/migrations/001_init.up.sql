@@ -0,0 +1,39 @@
+-- "if not exists" adopts databases made from the former sql/schema.sql by docker-entrypoint
+create table if not exists events
+(
+    id           varchar(32) primary key,
+    owner        varchar(32),
+    title        text,
+    notes        text,
+    start_at     timestamp with time zone,
+    end_at       timestamp with time zone,
+    alert_before bigint,
+    rrule        text not null default '',
+    exdates      text not null default '',
+    all_day      boolean not null default false,
+    timezone     text not null default ''
+);
+
+-- tables made from former versions of sql/schema.sql lack the columns added later
+alter table events
+    add column if not exists rrule    text not null default '',
+    add column if not exists exdates  text not null default '',
+    add column if not exists all_day  boolean not null default false,
+    add column if not exists timezone text not null default '';
+
+create index if not exists owner_idx on events (owner);
+create index if not exists start_idx on events using btree (start_at);
+create index if not exists end_idx on events using btree (end_at);
+
+create table if not exists attendees
+(
+    event_id varchar(32) references events (id) on delete cascade,
+    user_id  varchar(32),
+    status   varchar(16) not null default 'needs-action',
+    primary key (event_id, user_id)
+);
+
+alter table attendees
+    add column if not exists status varchar(16) not null default 'needs-action';
+
+create index if not exists attendee_user_idx on attendees (user_id);
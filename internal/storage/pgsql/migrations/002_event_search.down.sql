@@ -0,0 +1,2 @@
+drop index if exists search_idx;
+alter table events drop column if exists search;
@@ -0,0 +1,9 @@
+-- full-text search over titles and notes of events, the simple configuration neither stems
+-- nor drops stop words so that it matches the words as the in-memory storage does
+alter table events
+    add column if not exists search tsvector generated always as (
+        setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(notes, '')), 'B')
+    ) stored;
+
+create index if not exists search_idx on events using gin (search);
@@ -0,0 +1,3 @@
+alter table events
+    drop column if exists updated_at,
+    drop column if exists version;
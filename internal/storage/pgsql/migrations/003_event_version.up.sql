@@ -0,0 +1,4 @@
+-- versions of events for optimistic concurrency control, the events stored before count as stored once
+alter table events
+    add column if not exists version    bigint not null default 1,
+    add column if not exists updated_at timestamp with time zone not null default now();
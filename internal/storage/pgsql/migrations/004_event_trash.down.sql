@@ -0,0 +1,4 @@
+-- events in the trash would come back to life without the column, so they are purged
+delete from events where deleted_at is not null;
+drop index if exists events_deleted_at_idx;
+alter table events drop column if exists deleted_at;
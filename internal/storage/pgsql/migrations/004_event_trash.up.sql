@@ -0,0 +1,3 @@
+-- deleted events stay in the trash until they are restored or purged, live events have no deleted_at
+alter table events add column if not exists deleted_at timestamp with time zone;
+create index if not exists events_deleted_at_idx on events (deleted_at) where deleted_at is not null;
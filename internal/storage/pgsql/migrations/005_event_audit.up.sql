@@ -0,0 +1,13 @@
+-- append-only log of event changes, it outlives the events so it does not reference them
+create table if not exists event_audit
+(
+    id           bigserial primary key,
+    event_id     varchar(32) not null,
+    actor        text not null default '',
+    changed_at   timestamp with time zone not null,
+    op           varchar(16) not null,
+    before_event jsonb,
+    after_event  jsonb
+);
+
+create index if not exists event_audit_event_idx on event_audit (event_id, id);
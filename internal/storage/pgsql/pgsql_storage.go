@@ -1,10 +1,12 @@
 package pgsql
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
-	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/VladNF/calendar/internal/models"
@@ -14,89 +16,359 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
+// eventColumns are the columns of sqlEvent, the search column is only used in queries
+const eventColumns = "id, owner, title, notes, start_at, end_at, alert_before, rrule, exdates, all_day, timezone, " +
+	"version, updated_at, deleted_at"
+
 type sqlEvent struct {
-	ID          string    `db:"id"`
-	Title       string    `db:"title"`
-	StartsAt    time.Time `db:"start_at"`
-	EndsAt      time.Time `db:"end_at"`
-	Notes       string    `db:"notes"`
-	OwnerID     string    `db:"owner"`
-	AlertBefore int64     `db:"alert_before"`
+	ID          string       `db:"id"`
+	Title       string       `db:"title"`
+	StartsAt    time.Time    `db:"start_at"`
+	EndsAt      time.Time    `db:"end_at"`
+	Notes       string       `db:"notes"`
+	OwnerID     string       `db:"owner"`
+	AlertBefore int64        `db:"alert_before"`
+	RRule       string       `db:"rrule"`
+	ExDates     string       `db:"exdates"`
+	AllDay      bool         `db:"all_day"`
+	Timezone    string       `db:"timezone"`
+	Version     int64        `db:"version"`
+	UpdatedAt   time.Time    `db:"updated_at"`
+	DeletedAt   sql.NullTime `db:"deleted_at"`
+}
+
+func newSQLEvent(e *models.Event) sqlEvent {
+	dbEvent := sqlEvent{
+		ID:          e.ID,
+		Title:       e.Title,
+		StartsAt:    e.StartsAt,
+		EndsAt:      e.EndsAt,
+		Notes:       e.Notes,
+		OwnerID:     e.OwnerID,
+		AlertBefore: e.AlertBefore.Nanoseconds(),
+		AllDay:      e.AllDay,
+		Version:     e.Version,
+		UpdatedAt:   e.UpdatedAt,
+		DeletedAt:   sql.NullTime{Time: e.DeletedAt, Valid: e.IsDeleted()},
+	}
+	if e.Timezone != nil {
+		dbEvent.Timezone = e.Timezone.String()
+	}
+	if e.IsRecurring() {
+		dbEvent.RRule = e.Recurrence.String()
+		exDates := make([]string, 0, len(e.Recurrence.ExDates))
+		for _, d := range e.Recurrence.ExDates {
+			exDates = append(exDates, models.FormatICalTime(d))
+		}
+		dbEvent.ExDates = strings.Join(exDates, ",")
+	}
+	return dbEvent
 }
 
 func (e *sqlEvent) asModel() (*models.Event, error) {
-	event, err := models.NewEvent(e.ID, e.Title, e.StartsAt, e.EndsAt, e.OwnerID)
+	newEvent := models.NewEvent
+	if e.AllDay {
+		newEvent = models.NewAllDayEvent
+	}
+	event, err := newEvent(e.ID, e.Title, e.StartsAt.UTC(), e.EndsAt.UTC(), e.OwnerID)
 	if err != nil {
 		return nil, fmt.Errorf("%w: unexpected error %v", models.ErrDataError, err)
 	}
 	event.AlertBefore = time.Duration(e.AlertBefore)
 	event.Notes = e.Notes
+	event.Version = e.Version
+	event.UpdatedAt = e.UpdatedAt.UTC()
+	if e.DeletedAt.Valid {
+		event.DeletedAt = e.DeletedAt.Time.UTC()
+	}
+	if e.Timezone != "" {
+		if event.Timezone, err = models.LoadTimezone(e.Timezone); err != nil {
+			return nil, fmt.Errorf("%w: unexpected error %v", models.ErrDataError, err)
+		}
+	}
+	if e.RRule != "" {
+		if event.Recurrence, err = models.ParseRRule(e.RRule); err != nil {
+			return nil, fmt.Errorf("%w: unexpected error %v", models.ErrDataError, err)
+		}
+		for _, value := range strings.Split(e.ExDates, ",") {
+			if value == "" {
+				continue
+			}
+			d, err := models.ParseICalTime(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: unexpected error %v", models.ErrDataError, err)
+			}
+			event.Recurrence.ExDates = append(event.Recurrence.ExDates, d)
+		}
+	}
 	return event, nil
 }
 
+type sqlAttendee struct {
+	EventID string `db:"event_id"`
+	UserID  string `db:"user_id"`
+	Status  string `db:"status"`
+}
+
+// storedAt is the time of a write as precise as timestamps of postgres are
+func storedAt() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
 type PgStorage struct {
-	db *sqlx.DB
+	db           *sqlx.DB
+	queryTimeout time.Duration
+	// audit is the record the changes append to the audit log, see WithAudit
+	audit *models.AuditRecord
+}
+
+// withTimeout limits the time of a query, queries are only limited by ctx when the timeout is zero
+func (s *PgStorage) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, s.queryTimeout)
 }
 
-func (s *PgStorage) Get(id string) (*models.Event, error) {
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+func (s *PgStorage) Get(ctx context.Context, id string) (*models.Event, error) {
 	dbEvent := sqlEvent{}
-	query := "SELECT * FROM events WHERE id = $1"
-	if err := s.db.Get(&dbEvent, query, id); err != nil {
+	query := "SELECT " + eventColumns + " FROM events WHERE id = $1 AND deleted_at IS NULL"
+	qctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	if err := s.db.GetContext(qctx, &dbEvent, query, id); err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
 			return nil, models.ErrNotFound
 		default:
 			return nil, fmt.Errorf("%w: unexpected error %v", models.ErrNotFound, err)
 		}
+	} else if e, err := dbEvent.asModel(); err != nil {
+		return nil, err
 	} else {
-		return dbEvent.asModel()
+		return e, s.loadAttendees(ctx, []*models.Event{e})
 	}
 }
 
-func (s *PgStorage) Put(e *models.Event) error {
-	dbEvent := sqlEvent{
-		ID:          e.ID,
-		Title:       e.Title,
-		StartsAt:    e.StartsAt,
-		EndsAt:      e.EndsAt,
-		Notes:       e.Notes,
-		OwnerID:     e.OwnerID,
-		AlertBefore: e.AlertBefore.Nanoseconds(),
+// loadAttendees fills attendees of the events with a single query
+func (s *PgStorage) loadAttendees(ctx context.Context, events []*models.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	byID := make(map[string]*models.Event, len(events))
+	ids := make([]string, 0, len(events))
+	for _, e := range events {
+		byID[e.ID] = e
+		ids = append(ids, e.ID)
+	}
+	query, args, err := sqlx.In("SELECT * FROM attendees WHERE event_id IN (?) ORDER BY event_id, user_id", ids)
+	if err != nil {
+		return fmt.Errorf("%w: unexpected error -> %v", models.ErrDataError, err)
+	}
+	var attendees []sqlAttendee
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	if err = s.db.SelectContext(ctx, &attendees, s.db.Rebind(query), args...); err != nil {
+		return fmt.Errorf("%w: unexpected error -> %v", models.ErrDataError, err)
 	}
-	query := `INSERT INTO events 
-				(id, owner, title, notes, start_at, end_at, alert_before)
+	for _, a := range attendees {
+		e := byID[a.EventID]
+		e.Attendees = append(e.Attendees, models.Attendee{UserID: a.UserID, Status: models.ResponseStatus(a.Status)})
+	}
+	return nil
+}
+
+// errNotSaved is returned by save when the statement matched no rows
+var errNotSaved = errors.New("event was not saved")
+
+// Create inserts a new event, it fails with ErrAlreadyExists when an event has its ID
+func (s *PgStorage) Create(ctx context.Context, e *models.Event) error {
+	query := `INSERT INTO events
+				(id, owner, title, notes, start_at, end_at, alert_before, rrule, exdates, all_day, timezone,
+				version, updated_at)
 			VALUES
-				(:id, :owner, :title, :notes, :start_at, :end_at, :alert_before)
-			ON CONFLICT (id) DO UPDATE
+				(:id, :owner, :title, :notes, :start_at, :end_at, :alert_before, :rrule, :exdates, :all_day, :timezone,
+				:version, :updated_at)
+			ON CONFLICT (id) DO NOTHING`
+	if err := s.save(ctx, e, 1, query); errors.Is(err, errNotSaved) {
+		return fmt.Errorf("%w: event %s", models.ErrAlreadyExists, e.ID)
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Update replaces the event having its version, the version is compared and incremented by the same
+// statement so that concurrent writes conflict
+func (s *PgStorage) Update(ctx context.Context, e *models.Event) error {
+	query := `UPDATE events
 			SET
-				owner = EXCLUDED.owner, 
-				title = EXCLUDED.title, 
-				notes  = EXCLUDED.notes, 
-				start_at  = EXCLUDED.start_at, 
-				end_at  = EXCLUDED.end_at, 
-				alert_before  = EXCLUDED.alert_before`
-	if _, err := s.db.NamedExec(query, dbEvent); err != nil {
+				owner = :owner,
+				title = :title,
+				notes = :notes,
+				start_at = :start_at,
+				end_at = :end_at,
+				alert_before = :alert_before,
+				rrule = :rrule,
+				exdates = :exdates,
+				all_day = :all_day,
+				timezone = :timezone,
+				version = :version,
+				updated_at = :updated_at
+			WHERE id = :id AND version = :version - 1 AND deleted_at IS NULL`
+	if err := s.save(ctx, e, e.Version+1, query); errors.Is(err, errNotSaved) {
+		return s.missingOrConflict(ctx, e, false)
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// save stores the event with the version and its attendees in a transaction, it fails with errNotSaved
+// when the query matches no rows
+func (s *PgStorage) save(ctx context.Context, e *models.Event, version int64, query string) error {
+	dbEvent := newSQLEvent(e)
+	dbEvent.Version, dbEvent.UpdatedAt = version, storedAt()
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: put failed -> %v", models.ErrDataError, err)
+	}
+	defer tx.Rollback() //nolint:errcheck // it fails after commit only
+	if r, err := tx.NamedExecContext(ctx, query, dbEvent); err != nil {
 		return fmt.Errorf("%w: put failed -> %v", models.ErrDataError, err)
+	} else if rows, _ := r.RowsAffected(); rows == 0 {
+		return errNotSaved
+	}
+	if _, err = tx.ExecContext(ctx, "DELETE FROM attendees WHERE event_id = $1", e.ID); err != nil {
+		return fmt.Errorf("%w: put failed -> %v", models.ErrDataError, err)
+	}
+	for _, a := range e.Attendees {
+		query = "INSERT INTO attendees (event_id, user_id, status) VALUES ($1, $2, $3)"
+		if _, err = tx.ExecContext(ctx, query, e.ID, a.UserID, string(a.Status)); err != nil {
+			return fmt.Errorf("%w: put failed -> %v", models.ErrDataError, err)
+		}
 	}
+	after := *e
+	after.Version, after.UpdatedAt = dbEvent.Version, dbEvent.UpdatedAt
+	if err = s.appendAudit(ctx, tx, &after); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%w: put failed -> %v", models.ErrDataError, err)
+	}
+	e.Version, e.UpdatedAt = dbEvent.Version, dbEvent.UpdatedAt
 	return nil
 }
 
-func (s *PgStorage) Delete(e *models.Event) error {
-	query := "DELETE FROM events WHERE id = $1"
-	if r, err := s.db.Exec(query, e.ID); err != nil {
+// missingOrConflict tells why a statement matched no rows of the event, deleted tells whether the statement
+// looked for the event in the trash
+func (s *PgStorage) missingOrConflict(ctx context.Context, e *models.Event, deleted bool) error {
+	var count int
+	query := "SELECT COUNT(*) FROM events WHERE id = $1 AND (deleted_at IS NOT NULL) = $2"
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	if err := s.db.GetContext(ctx, &count, query, e.ID, deleted); err != nil {
 		return fmt.Errorf("%w: unexpected error -> %v", models.ErrDataError, err)
-	} else if rows, _ := r.RowsAffected(); rows == 0 {
-		return models.ErrNotFound
+	} else if count > 0 {
+		return fmt.Errorf("%w: event %s is not at version %d", models.ErrConflict, e.ID, e.Version)
+	}
+	return models.ErrNotFound
+}
+
+// Delete moves the event having its version into the trash, a zero version deletes any
+func (s *PgStorage) Delete(ctx context.Context, e *models.Event) error {
+	query := `UPDATE events SET deleted_at = $3, updated_at = $3, version = version + 1
+			WHERE id = $1 AND deleted_at IS NULL AND ($2::bigint = 0 OR version = $2)`
+	return s.changeState(ctx, e, false, query, e.ID, e.Version, storedAt())
+}
+
+// GetDeleted returns the event from the trash
+func (s *PgStorage) GetDeleted(ctx context.Context, id string) (*models.Event, error) {
+	query := "SELECT " + eventColumns + " FROM events WHERE id = $1 AND deleted_at IS NOT NULL"
+	events, err := s.queryEvents(ctx, query, id)
+	if err != nil {
+		return nil, err
+	} else if len(events) == 0 {
+		return nil, models.ErrNotFound
 	}
+	return events[0], nil
+}
+
+// ListDeleted returns the events in the trash, the last deleted go first, an empty owner lists them all
+func (s *PgStorage) ListDeleted(ctx context.Context, owner string) ([]*models.Event, error) {
+	query := "SELECT " + eventColumns + ` FROM events
+				WHERE deleted_at IS NOT NULL AND ($1 = '' OR owner = $1) ORDER BY deleted_at DESC, id`
+	return s.queryEvents(ctx, query, owner)
+}
+
+// Restore brings the event having its version back from the trash, a zero version restores any
+func (s *PgStorage) Restore(ctx context.Context, e *models.Event) error {
+	query := `UPDATE events SET deleted_at = NULL, updated_at = $3, version = version + 1
+			WHERE id = $1 AND deleted_at IS NOT NULL AND ($2::bigint = 0 OR version = $2)`
+	return s.changeState(ctx, e, true, query, e.ID, e.Version, storedAt())
+}
+
+// Purge removes the event having its version from the trash for good, a zero version purges any,
+// attendees are removed by the foreign key cascade
+func (s *PgStorage) Purge(ctx context.Context, e *models.Event) error {
+	query := "DELETE FROM events WHERE id = $1 AND deleted_at IS NOT NULL AND ($2::bigint = 0 OR version = $2)"
+	return s.changeState(ctx, e, true, query, e.ID, e.Version)
+}
 
+// changeState runs the statement changing the event in a transaction, deleted tells whether it changes
+// an event of the trash
+func (s *PgStorage) changeState(
+	ctx context.Context, e *models.Event, deleted bool, query string, args ...interface{},
+) error {
+	qctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	tx, err := s.db.BeginTxx(qctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: unexpected error -> %v", models.ErrDataError, err)
+	}
+	defer tx.Rollback() //nolint:errcheck // it fails after commit only
+	if r, err := tx.ExecContext(qctx, query, args...); err != nil {
+		return fmt.Errorf("%w: unexpected error -> %v", models.ErrDataError, err)
+	} else if rows, _ := r.RowsAffected(); rows == 0 {
+		tx.Rollback() //nolint:errcheck // it releases the connection missingOrConflict may need
+		return s.missingOrConflict(ctx, e, deleted)
+	}
+	if s.audit != nil {
+		after, err := s.storedState(qctx, tx, e.ID)
+		if err != nil {
+			return err
+		}
+		if err = s.appendAudit(qctx, tx, after); err != nil {
+			return err
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%w: unexpected error -> %v", models.ErrDataError, err)
+	}
 	return nil
 }
 
-func (s *PgStorage) getEventList(lBound time.Time, uBound time.Time) ([]*models.Event, error) {
-	query := `SELECT * from events AS e 
-				WHERE e.start_at > to_timestamp($1) AND e.start_at < to_timestamp($2)
-				ORDER BY e.start_at`
-	if rows, err := s.db.Queryx(query, lBound.Unix(), uBound.Unix()); err != nil {
+// PurgeDeleted removes the events deleted before the time from the trash for good
+func (s *PgStorage) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	r, err := s.db.ExecContext(ctx, "DELETE FROM events WHERE deleted_at < $1", before)
+	if err != nil {
+		return 0, fmt.Errorf("%w: purge failed -> %v", models.ErrDataError, err)
+	}
+	rows, _ := r.RowsAffected()
+	return int(rows), nil
+}
+
+func (s *PgStorage) queryEvents(ctx context.Context, query string, args ...interface{}) ([]*models.Event, error) {
+	qctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	if rows, err := s.db.QueryxContext(qctx, query, args...); err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
 			return nil, models.ErrNotFound
@@ -115,53 +387,224 @@ func (s *PgStorage) getEventList(lBound time.Time, uBound time.Time) ([]*models.
 				results = append(results, m)
 			}
 		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("%w: unexpected error -> %v", models.ErrDataError, err)
+		}
+		rows.Close()
+		if err := s.loadAttendees(ctx, results); err != nil {
+			return nil, err
+		}
 		return results, nil
 	}
 }
 
-func (s *PgStorage) GetDayList(d time.Time) ([]*models.Event, error) {
-	yy, mm, dd := d.Date()
-	lBound := time.Date(yy, mm, dd, 0, 0, 0, -1, d.Location())
-	uBound := time.Date(yy, mm, dd+1, 0, 0, 0, 0, d.Location())
-	return s.getEventList(lBound, uBound)
+// windowCondition matches events overlapping [lBound, uBound) and recurring series which may do so,
+// all-day events are matched against the window as floating time
+func windowCondition(lBound time.Time, uBound time.Time) (string, []interface{}) {
+	flBound, fuBound := models.Floating(lBound), models.Floating(uBound)
+	condition := `e.deleted_at IS NULL
+					AND ((e.rrule = '' AND NOT e.all_day AND e.start_at < ? AND (e.end_at > ? OR e.start_at >= ?))
+					OR (e.rrule = '' AND e.all_day AND e.start_at < ? AND e.end_at > ?)
+					OR (e.rrule <> '' AND e.start_at < GREATEST(?, ?)))`
+	return condition, []interface{}{uBound, lBound, lBound, fuBound, flBound, uBound, fuBound}
+}
+
+// getEventList returns events and occurrences of recurring events overlapping [lBound, uBound),
+// no owners match events of any owner.
+func (s *PgStorage) getEventList(
+	ctx context.Context, owners []string, lBound time.Time, uBound time.Time,
+) ([]*models.Event, error) {
+	condition, args := windowCondition(lBound, uBound)
+	query := "SELECT " + eventColumns + " FROM events AS e WHERE " + condition
+	if len(owners) > 0 {
+		query += " AND e.owner IN (?)"
+		args = append(args, owners)
+	}
+	query, args, err := sqlx.In(query+" ORDER BY e.start_at", args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unexpected error -> %v", models.ErrDataError, err)
+	}
+	events, err := s.queryEvents(ctx, s.db.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	var results []*models.Event
+	for _, e := range events {
+		results = append(results, e.Occurrences(lBound, uBound)...)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].StartsAt.Before(results[j].StartsAt) })
+	return results, nil
+}
+
+func (s *PgStorage) GetDayList(ctx context.Context, d time.Time) ([]*models.Event, error) {
+	lBound, uBound := models.DayWindow(d)
+	return s.getEventList(ctx, nil, lBound, uBound)
+}
+
+func (s *PgStorage) GetWeekList(ctx context.Context, d time.Time, weekStart time.Weekday) ([]*models.Event, error) {
+	lBound, uBound := models.WeekWindow(d, weekStart)
+	return s.getEventList(ctx, nil, lBound, uBound)
+}
+
+func (s *PgStorage) GetMonthList(ctx context.Context, d time.Time) ([]*models.Event, error) {
+	lBound, uBound := models.MonthWindow(d)
+	return s.getEventList(ctx, nil, lBound, uBound)
 }
 
-func (s *PgStorage) GetWeekList(d time.Time) ([]*models.Event, error) {
-	yy, mm, dd := d.Date()
-	sunday := time.Date(yy, mm, dd+int(time.Sunday-d.Weekday()), 0, 0, 0, 0, d.Location())
-	saturday := sunday.AddDate(0, 0, 7)
-	return s.getEventList(sunday, saturday)
+func (s *PgStorage) IsBusy(ctx context.Context, d1, d2 time.Time) (bool, error) {
+	return s.isBusy(ctx, "", d1, d2, "")
 }
 
-func (s *PgStorage) GetMonthList(d time.Time) ([]*models.Event, error) {
-	yy, mm, _ := d.Date()
-	lBound := time.Date(yy, mm, 1, 0, 0, 0, -1, d.Location())
-	uBound := time.Date(yy, mm+1, 1, 0, 0, 0, -1, d.Location())
-	return s.getEventList(lBound, uBound)
+func (s *PgStorage) GetDayListByOwner(ctx context.Context, owner string, d time.Time) ([]*models.Event, error) {
+	lBound, uBound := models.DayWindow(d)
+	return s.getEventList(ctx, []string{owner}, lBound, uBound)
 }
 
-func (s *PgStorage) IsBusy(d1, d2 time.Time) (bool, error) {
+func (s *PgStorage) GetWeekListByOwner(
+	ctx context.Context, owner string, d time.Time, weekStart time.Weekday,
+) ([]*models.Event, error) {
+	lBound, uBound := models.WeekWindow(d, weekStart)
+	return s.getEventList(ctx, []string{owner}, lBound, uBound)
+}
+
+func (s *PgStorage) GetMonthListByOwner(ctx context.Context, owner string, d time.Time) ([]*models.Event, error) {
+	lBound, uBound := models.MonthWindow(d)
+	return s.getEventList(ctx, []string{owner}, lBound, uBound)
+}
+
+func (s *PgStorage) IsOwnerBusy(ctx context.Context, owner string, d1, d2 time.Time, exceptID string) (bool, error) {
+	return s.isBusy(ctx, owner, d1, d2, exceptID)
+}
+
+// GetRangeListByOwners returns events of the owners and occurrences of their recurring events
+// overlapping [lBound, uBound) with a single query
+func (s *PgStorage) GetRangeListByOwners(
+	ctx context.Context, owners []string, lBound, uBound time.Time,
+) ([]*models.Event, error) {
+	if len(owners) == 0 {
+		return nil, nil
+	}
+	return s.getEventList(ctx, owners, lBound, uBound)
+}
+
+// GetRangePage returns up to size events and occurrences of recurring events overlapping [lBound, uBound)
+// which follow the cursor, an empty owner lists events of all owners. Single events are paged by the query
+// whereas recurring series are expanded and merged with them.
+func (s *PgStorage) GetRangePage(
+	ctx context.Context, owner string, lBound, uBound time.Time, after *models.Cursor, size int,
+) (models.Page, error) {
+	flBound, fuBound := models.Floating(lBound), models.Floating(uBound)
+	query := "SELECT " + eventColumns + ` FROM events AS e
+				WHERE e.deleted_at IS NULL
+					AND ((e.rrule = '' AND NOT e.all_day AND e.start_at < ? AND (e.end_at > ? OR e.start_at >= ?))
+					OR (e.rrule = '' AND e.all_day AND e.start_at < ? AND e.end_at > ?))`
+	args := []interface{}{uBound, lBound, lBound, fuBound, flBound}
+	if owner != "" {
+		query += " AND e.owner = ?"
+		args = append(args, owner)
+	}
+	if after != nil {
+		query += " AND (e.start_at, e.id) > (?, ?)"
+		args = append(args, after.StartsAt, after.ID)
+	}
+	query += " ORDER BY e.start_at, e.id LIMIT ?"
+	args = append(args, size+1)
+	events, err := s.queryEvents(ctx, s.db.Rebind(query), args...)
+	if err != nil {
+		return models.Page{}, err
+	}
+
+	query = "SELECT " + eventColumns + ` FROM events AS e
+				WHERE e.deleted_at IS NULL AND e.rrule <> '' AND e.start_at < GREATEST($1, $2)
+					AND ($3 = '' OR e.owner = $3)`
+	series, err := s.queryEvents(ctx, query, uBound, fuBound, owner)
+	if err != nil {
+		return models.Page{}, err
+	}
+	for _, e := range series {
+		events = append(events, e.Occurrences(lBound, uBound)...)
+	}
+	return models.Paginate(events, after, size), nil
+}
+
+// Search returns events having all the words of the search text ordered by start, the words are matched
+// by the GIN index of the search column and recurring events are expanded into occurrences
+// when the search has a date range
+func (s *PgStorage) Search(ctx context.Context, q models.SearchQuery) ([]*models.Event, error) {
+	terms := models.SearchTerms(q.Text)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	// the terms have letters and digits only, so they are safe to join into a tsquery
+	query := "SELECT " + eventColumns + ` FROM events AS e
+				WHERE e.deleted_at IS NULL AND e.search @@ to_tsquery('simple', ?)`
+	args := []interface{}{strings.Join(terms, " & ")}
+	if q.Owner != "" {
+		query += " AND e.owner = ?"
+		args = append(args, q.Owner)
+	}
+	if !q.HasRange() {
+		query += " ORDER BY e.start_at, e.id LIMIT ?"
+		return s.queryEvents(ctx, s.db.Rebind(query), append(args, q.Limit)...)
+	}
+
+	condition, windowArgs := windowCondition(q.From, q.To)
+	query += " AND " + condition
+	events, err := s.queryEvents(ctx, s.db.Rebind(query), append(args, windowArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	var results []*models.Event
+	for _, e := range events {
+		results = append(results, e.Occurrences(q.From, q.To)...)
+	}
+	models.SortListing(results)
+	if len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+	return results, nil
+}
+
+// GetListByOwner returns all events of the owner, recurring events are not expanded
+func (s *PgStorage) GetListByOwner(ctx context.Context, owner string) ([]*models.Event, error) {
+	query := "SELECT " + eventColumns + " FROM events WHERE owner = $1 AND deleted_at IS NULL ORDER BY start_at"
+	return s.queryEvents(ctx, query, owner)
+}
+
+// isBusy checks overlaps with timed events, all-day events do not make the time busy
+func (s *PgStorage) isBusy(ctx context.Context, owner string, d1, d2 time.Time, exceptID string) (bool, error) {
+	if !d1.Before(d2) {
+		return false, fmt.Errorf("%w: start must be before end", models.ErrValueError)
+	}
 	var overlapCount int
-	query := "SELECT COUNT(*) FROM events AS e WHERE (e.start_at, e.end_at) OVERLAPS ($1, $2)"
-	if err := s.db.Get(&overlapCount, query, d1.Unix(), d2.Unix()); err != nil {
+	query := `SELECT COUNT(*) FROM events AS e
+				WHERE e.deleted_at IS NULL AND e.rrule = '' AND NOT e.all_day AND (e.start_at, e.end_at) OVERLAPS ($1, $2)
+					AND ($3 = '' OR e.owner = $3) AND e.id <> $4`
+	qctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	if err := s.db.GetContext(qctx, &overlapCount, query, d1, d2, owner, exceptID); err != nil {
 		return false, fmt.Errorf("%w: unexpected error -> %v", models.ErrDataError, err)
 	}
+	if overlapCount > 0 {
+		return true, nil
+	}
 
-	return overlapCount > 0, nil
-}
+	query = "SELECT " + eventColumns + ` FROM events AS e
+				WHERE e.deleted_at IS NULL AND e.rrule <> '' AND NOT e.all_day AND e.start_at < $1
+					AND ($2 = '' OR e.owner = $2) AND e.id <> $3`
+	series, err := s.queryEvents(ctx, query, d2, owner, exceptID)
+	if err != nil {
+		return false, err
+	}
+	for _, e := range series {
+		if e.Overlaps(d1, d2) {
+			return true, nil
+		}
+	}
 
-func NewPgSQLStorage(db *sqlx.DB) models.EventsRepo {
-	return &PgStorage{db}
+	return false, nil
 }
 
-func NewPgSQLConnection() (*sqlx.DB, error) {
-	dsn := fmt.Sprintf(
-		"postgres://%s:%s@%s:%s/%s",
-		os.Getenv("POSTGRES_USER"),
-		os.Getenv("POSTGRES_PASSWORD"),
-		os.Getenv("POSTGRES_HOST"),
-		os.Getenv("POSTGRES_PORT"),
-		os.Getenv("POSTGRES_DB"),
-	)
-	return sqlx.Open("pgx", dsn)
+func NewPgSQLStorage(db *sqlx.DB, queryTimeout time.Duration) models.EventsRepo {
+	return &PgStorage{db: db, queryTimeout: queryTimeout}
 }
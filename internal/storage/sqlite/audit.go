@@ -0,0 +1,136 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/VladNF/calendar/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+type sqlAuditRecord struct {
+	EventID   string         `db:"event_id"`
+	Actor     string         `db:"actor"`
+	ChangedAt int64          `db:"changed_at"`
+	Op        string         `db:"op"`
+	Before    sql.NullString `db:"before_event"`
+	After     sql.NullString `db:"after_event"`
+}
+
+// jsonSnapshot returns the JSON document of the event, a nil event is stored as null
+func jsonSnapshot(e *models.Event) (sql.NullString, error) {
+	data, err := models.MarshalEvent(e)
+	return sql.NullString{String: string(data), Valid: data != nil}, err
+}
+
+func newSQLAuditRecord(r *models.AuditRecord) (sqlAuditRecord, error) {
+	dbRecord := sqlAuditRecord{EventID: r.EventID, Actor: r.Actor, ChangedAt: nanos(r.At), Op: string(r.Op)}
+	var err error
+	if dbRecord.Before, err = jsonSnapshot(r.Before); err != nil {
+		return dbRecord, err
+	}
+	dbRecord.After, err = jsonSnapshot(r.After)
+	return dbRecord, err
+}
+
+func (r *sqlAuditRecord) asModel() (*models.AuditRecord, error) {
+	record := &models.AuditRecord{
+		EventID: r.EventID,
+		Actor:   r.Actor,
+		At:      time.Unix(0, r.ChangedAt).UTC(),
+		Op:      models.AuditOp(r.Op),
+	}
+	var err error
+	if record.Before, err = models.UnmarshalEvent([]byte(r.Before.String)); err != nil {
+		return nil, fmt.Errorf("%w: unexpected error %v", models.ErrDataError, err)
+	}
+	if record.After, err = models.UnmarshalEvent([]byte(r.After.String)); err != nil {
+		return nil, fmt.Errorf("%w: unexpected error %v", models.ErrDataError, err)
+	}
+	return record, nil
+}
+
+const insertAuditQuery = `INSERT INTO event_audit (event_id, actor, changed_at, op, before_event, after_event)
+			VALUES (:event_id, :actor, :changed_at, :op, :before_event, :after_event)`
+
+// Append inserts the record into the audit log
+func (s *SQLiteStorage) Append(ctx context.Context, r *models.AuditRecord) error {
+	dbRecord, err := newSQLAuditRecord(r)
+	if err != nil {
+		return fmt.Errorf("%w: audit failed -> %v", models.ErrDataError, err)
+	}
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	if _, err = s.db.NamedExecContext(ctx, insertAuditQuery, dbRecord); err != nil {
+		return fmt.Errorf("%w: audit failed -> %v", models.ErrDataError, err)
+	}
+	return nil
+}
+
+// History returns the audit records of the event in the order they were appended
+func (s *SQLiteStorage) History(ctx context.Context, eventID string) ([]*models.AuditRecord, error) {
+	var dbRecords []sqlAuditRecord
+	query := "SELECT event_id, actor, changed_at, op, before_event, after_event FROM event_audit " +
+		"WHERE event_id = ? ORDER BY id"
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	if err := s.db.SelectContext(ctx, &dbRecords, query, eventID); err != nil {
+		return nil, fmt.Errorf("%w: unexpected error %v", models.ErrDataError, err)
+	}
+	records := make([]*models.AuditRecord, 0, len(dbRecords))
+	for i := range dbRecords {
+		record, err := dbRecords[i].asModel()
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// WithAudit returns the storage appending the record of its changes in their transactions
+func (s *SQLiteStorage) WithAudit(r *models.AuditRecord) models.EventsRepo {
+	audited := *s
+	audited.audit = r
+	return &audited
+}
+
+// appendAudit inserts the record of the change in the transaction of the change when the storage has one,
+// after is the event the change stored and nil when the change removed it
+func (s *SQLiteStorage) appendAudit(ctx context.Context, tx *sqlx.Tx, after *models.Event) error {
+	if s.audit == nil {
+		return nil
+	}
+	r := *s.audit
+	r.After = after
+	dbRecord, err := newSQLAuditRecord(&r)
+	if err != nil {
+		return fmt.Errorf("%w: audit failed -> %v", models.ErrDataError, err)
+	}
+	if _, err = tx.NamedExecContext(ctx, insertAuditQuery, dbRecord); err != nil {
+		return fmt.Errorf("%w: audit failed -> %v", models.ErrDataError, err)
+	}
+	return nil
+}
+
+// storedState reads the event in the transaction which changed its state, it returns nil when the event
+// is removed, state changes keep attendees so they are taken from the event before the change
+func (s *SQLiteStorage) storedState(ctx context.Context, tx *sqlx.Tx, id string) (*models.Event, error) {
+	var dbEvents []sqlEvent
+	if err := tx.SelectContext(ctx, &dbEvents, "SELECT "+eventColumns+" FROM events WHERE id = ?", id); err != nil {
+		return nil, fmt.Errorf("%w: unexpected error -> %v", models.ErrDataError, err)
+	}
+	if len(dbEvents) == 0 {
+		return nil, nil
+	}
+	event, err := dbEvents[0].asModel()
+	if err != nil {
+		return nil, err
+	}
+	if s.audit.Before != nil {
+		event.Attendees = s.audit.Before.Attendees
+	}
+	return event, nil
+}
@@ -0,0 +1,31 @@
+-- times are unix nanoseconds so that they compare as numbers, all-day events keep floating times in UTC;
+-- terms are the search terms of the event separated and surrounded by spaces
+create table if not exists events
+(
+    id           text primary key,
+    owner        text not null default '',
+    title        text not null default '',
+    notes        text not null default '',
+    start_at     integer not null,
+    end_at       integer not null,
+    alert_before integer not null default 0,
+    rrule        text not null default '',
+    exdates      text not null default '',
+    all_day      boolean not null default false,
+    timezone     text not null default '',
+    terms        text not null default ''
+);
+
+create index if not exists owner_idx on events (owner);
+create index if not exists start_idx on events (start_at, id);
+create index if not exists end_idx on events (end_at);
+
+create table if not exists attendees
+(
+    event_id text not null references events (id) on delete cascade,
+    user_id  text not null,
+    status   text not null default 'needs-action',
+    primary key (event_id, user_id)
+);
+
+create index if not exists attendee_user_idx on attendees (user_id);
@@ -0,0 +1,3 @@
+-- versions of events for optimistic concurrency control, the events stored before count as stored once
+alter table events add column version integer not null default 1;
+alter table events add column updated_at integer not null default 0;
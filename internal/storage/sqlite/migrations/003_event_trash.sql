@@ -0,0 +1,3 @@
+-- deleted events stay in the trash until they are restored or purged, live events have a zero deleted_at
+alter table events add column deleted_at integer not null default 0;
+create index events_deleted_at_idx on events (deleted_at) where deleted_at <> 0;
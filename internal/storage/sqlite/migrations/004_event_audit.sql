@@ -0,0 +1,13 @@
+-- append-only log of event changes, it outlives the events so it does not reference them
+create table event_audit
+(
+    id           integer primary key autoincrement,
+    event_id     text not null,
+    actor        text not null default '',
+    changed_at   integer not null,
+    op           text not null,
+    before_event text,
+    after_event  text
+);
+
+create index event_audit_event_idx on event_audit (event_id, id);
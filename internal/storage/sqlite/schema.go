@@ -0,0 +1,61 @@
+package sqlite
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/jmoiron/sqlx"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrate applies the migrations the database file lacks, the version of the file is kept in user_version
+// so that a file made by an older build is upgraded when it is opened
+func migrate(db *sqlx.DB) error {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+
+	var version int
+	if err = db.Get(&version, "PRAGMA user_version"); err != nil {
+		return fmt.Errorf("schema version failed -> %w", err)
+	}
+	if version > len(names) {
+		return fmt.Errorf("schema version %d is newer than the migrations of this build", version)
+	}
+	for i, name := range names[version:] {
+		script, err := migrationFiles.ReadFile(path.Join("migrations", name))
+		if err != nil {
+			return err
+		}
+		if err = applyMigration(db, string(script), version+i+1); err != nil {
+			return fmt.Errorf("migration %s failed -> %w", name, err)
+		}
+	}
+	return nil
+}
+
+func applyMigration(db *sqlx.DB, script string, version int) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck // it fails after commit only
+	if _, err = tx.Exec(script); err != nil {
+		return err
+	}
+	// pragmas take no parameters, the version is a number
+	if _, err = tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", version)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
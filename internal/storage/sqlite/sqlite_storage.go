@@ -0,0 +1,678 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/VladNF/calendar/internal/models"
+	"github.com/jmoiron/sqlx"
+
+	// init driver, it is written in pure Go and needs no cgo.
+	_ "modernc.org/sqlite"
+)
+
+// eventColumns are the columns of sqlEvent, the terms column is only used in queries
+const eventColumns = "id, owner, title, notes, start_at, end_at, alert_before, rrule, exdates, all_day, timezone, " +
+	"version, updated_at, deleted_at"
+
+type sqlEvent struct {
+	ID          string `db:"id"`
+	Title       string `db:"title"`
+	StartsAt    int64  `db:"start_at"`
+	EndsAt      int64  `db:"end_at"`
+	Notes       string `db:"notes"`
+	OwnerID     string `db:"owner"`
+	AlertBefore int64  `db:"alert_before"`
+	RRule       string `db:"rrule"`
+	ExDates     string `db:"exdates"`
+	AllDay      bool   `db:"all_day"`
+	Timezone    string `db:"timezone"`
+	Terms       string `db:"terms"`
+	Version     int64  `db:"version"`
+	UpdatedAt   int64  `db:"updated_at"`
+	DeletedAt   int64  `db:"deleted_at"`
+}
+
+func newSQLEvent(e *models.Event) sqlEvent {
+	dbEvent := sqlEvent{
+		ID:          e.ID,
+		Title:       e.Title,
+		StartsAt:    e.StartsAt.UnixNano(),
+		EndsAt:      e.EndsAt.UnixNano(),
+		Notes:       e.Notes,
+		OwnerID:     e.OwnerID,
+		AlertBefore: e.AlertBefore.Nanoseconds(),
+		AllDay:      e.AllDay,
+		Terms:       " " + strings.Join(e.Terms(), " ") + " ",
+		Version:     e.Version,
+		UpdatedAt:   e.UpdatedAt.UnixNano(),
+	}
+	if e.IsDeleted() {
+		dbEvent.DeletedAt = e.DeletedAt.UnixNano()
+	}
+	if e.Timezone != nil {
+		dbEvent.Timezone = e.Timezone.String()
+	}
+	if e.IsRecurring() {
+		dbEvent.RRule = e.Recurrence.String()
+		exDates := make([]string, 0, len(e.Recurrence.ExDates))
+		for _, d := range e.Recurrence.ExDates {
+			exDates = append(exDates, models.FormatICalTime(d))
+		}
+		dbEvent.ExDates = strings.Join(exDates, ",")
+	}
+	return dbEvent
+}
+
+func (e *sqlEvent) asModel() (*models.Event, error) {
+	newEvent := models.NewEvent
+	if e.AllDay {
+		newEvent = models.NewAllDayEvent
+	}
+	event, err := newEvent(e.ID, e.Title, time.Unix(0, e.StartsAt).UTC(), time.Unix(0, e.EndsAt).UTC(), e.OwnerID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unexpected error %v", models.ErrDataError, err)
+	}
+	event.AlertBefore = time.Duration(e.AlertBefore)
+	event.Notes = e.Notes
+	event.Version = e.Version
+	if e.UpdatedAt != 0 {
+		event.UpdatedAt = time.Unix(0, e.UpdatedAt).UTC()
+	}
+	if e.DeletedAt != 0 {
+		event.DeletedAt = time.Unix(0, e.DeletedAt).UTC()
+	}
+	if e.Timezone != "" {
+		if event.Timezone, err = models.LoadTimezone(e.Timezone); err != nil {
+			return nil, fmt.Errorf("%w: unexpected error %v", models.ErrDataError, err)
+		}
+	}
+	if e.RRule != "" {
+		if event.Recurrence, err = models.ParseRRule(e.RRule); err != nil {
+			return nil, fmt.Errorf("%w: unexpected error %v", models.ErrDataError, err)
+		}
+		for _, value := range strings.Split(e.ExDates, ",") {
+			if value == "" {
+				continue
+			}
+			d, err := models.ParseICalTime(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: unexpected error %v", models.ErrDataError, err)
+			}
+			event.Recurrence.ExDates = append(event.Recurrence.ExDates, d)
+		}
+	}
+	return event, nil
+}
+
+type sqlAttendee struct {
+	EventID string `db:"event_id"`
+	UserID  string `db:"user_id"`
+	Status  string `db:"status"`
+}
+
+// nanos is the stored form of times used as query arguments
+func nanos(t time.Time) int64 {
+	return t.UnixNano()
+}
+
+type SQLiteStorage struct {
+	db           *sqlx.DB
+	queryTimeout time.Duration
+	// audit is the record the changes append to the audit log, see WithAudit
+	audit *models.AuditRecord
+}
+
+// withTimeout limits the time of a query, queries are only limited by ctx when the timeout is zero
+func (s *SQLiteStorage) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.queryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.queryTimeout)
+}
+
+func (s *SQLiteStorage) Get(ctx context.Context, id string) (*models.Event, error) {
+	dbEvent := sqlEvent{}
+	query := "SELECT " + eventColumns + " FROM events WHERE id = ? AND deleted_at = 0"
+	qctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	if err := s.db.GetContext(qctx, &dbEvent, query, id); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, models.ErrNotFound
+		default:
+			return nil, fmt.Errorf("%w: unexpected error %v", models.ErrNotFound, err)
+		}
+	} else if e, err := dbEvent.asModel(); err != nil {
+		return nil, err
+	} else {
+		return e, s.loadAttendees(ctx, []*models.Event{e})
+	}
+}
+
+// loadAttendees fills attendees of the events with a single query
+func (s *SQLiteStorage) loadAttendees(ctx context.Context, events []*models.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	byID := make(map[string]*models.Event, len(events))
+	ids := make([]string, 0, len(events))
+	for _, e := range events {
+		byID[e.ID] = e
+		ids = append(ids, e.ID)
+	}
+	query, args, err := sqlx.In("SELECT * FROM attendees WHERE event_id IN (?) ORDER BY event_id, user_id", ids)
+	if err != nil {
+		return fmt.Errorf("%w: unexpected error -> %v", models.ErrDataError, err)
+	}
+	var attendees []sqlAttendee
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	if err = s.db.SelectContext(ctx, &attendees, query, args...); err != nil {
+		return fmt.Errorf("%w: unexpected error -> %v", models.ErrDataError, err)
+	}
+	for _, a := range attendees {
+		e := byID[a.EventID]
+		e.Attendees = append(e.Attendees, models.Attendee{UserID: a.UserID, Status: models.ResponseStatus(a.Status)})
+	}
+	return nil
+}
+
+// errNotSaved is returned by save when the statement matched no rows
+var errNotSaved = errors.New("event was not saved")
+
+// Create inserts a new event, it fails with ErrAlreadyExists when an event has its ID
+func (s *SQLiteStorage) Create(ctx context.Context, e *models.Event) error {
+	query := `INSERT INTO events
+				(id, owner, title, notes, start_at, end_at, alert_before, rrule, exdates, all_day, timezone, terms,
+				version, updated_at)
+			VALUES
+				(:id, :owner, :title, :notes, :start_at, :end_at, :alert_before, :rrule, :exdates, :all_day,
+				:timezone, :terms, :version, :updated_at)
+			ON CONFLICT (id) DO NOTHING`
+	if err := s.save(ctx, e, 1, query); errors.Is(err, errNotSaved) {
+		return fmt.Errorf("%w: event %s", models.ErrAlreadyExists, e.ID)
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Update replaces the event having its version, the version is compared and incremented by the same
+// statement so that concurrent writes conflict
+func (s *SQLiteStorage) Update(ctx context.Context, e *models.Event) error {
+	query := `UPDATE events
+			SET
+				owner = :owner,
+				title = :title,
+				notes = :notes,
+				start_at = :start_at,
+				end_at = :end_at,
+				alert_before = :alert_before,
+				rrule = :rrule,
+				exdates = :exdates,
+				all_day = :all_day,
+				timezone = :timezone,
+				terms = :terms,
+				version = :version,
+				updated_at = :updated_at
+			WHERE id = :id AND version = :version - 1 AND deleted_at = 0`
+	if err := s.save(ctx, e, e.Version+1, query); errors.Is(err, errNotSaved) {
+		return s.missingOrConflict(ctx, e, false)
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// save stores the event with the version and its attendees in a transaction, it fails with errNotSaved
+// when the query matches no rows
+func (s *SQLiteStorage) save(ctx context.Context, e *models.Event, version int64, query string) error {
+	dbEvent := newSQLEvent(e)
+	updatedAt := time.Now().UTC()
+	dbEvent.Version, dbEvent.UpdatedAt = version, updatedAt.UnixNano()
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: put failed -> %v", models.ErrDataError, err)
+	}
+	defer tx.Rollback() //nolint:errcheck // it fails after commit only
+	if r, err := tx.NamedExecContext(ctx, query, dbEvent); err != nil {
+		return fmt.Errorf("%w: put failed -> %v", models.ErrDataError, err)
+	} else if rows, _ := r.RowsAffected(); rows == 0 {
+		return errNotSaved
+	}
+	if _, err = tx.ExecContext(ctx, "DELETE FROM attendees WHERE event_id = ?", e.ID); err != nil {
+		return fmt.Errorf("%w: put failed -> %v", models.ErrDataError, err)
+	}
+	for _, a := range e.Attendees {
+		query = "INSERT INTO attendees (event_id, user_id, status) VALUES (?, ?, ?)"
+		if _, err = tx.ExecContext(ctx, query, e.ID, a.UserID, string(a.Status)); err != nil {
+			return fmt.Errorf("%w: put failed -> %v", models.ErrDataError, err)
+		}
+	}
+	after := *e
+	after.Version, after.UpdatedAt = dbEvent.Version, updatedAt
+	if err = s.appendAudit(ctx, tx, &after); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%w: put failed -> %v", models.ErrDataError, err)
+	}
+	e.Version, e.UpdatedAt = dbEvent.Version, updatedAt
+	return nil
+}
+
+// missingOrConflict tells why a statement matched no rows of the event, deleted tells whether the statement
+// looked for the event in the trash
+func (s *SQLiteStorage) missingOrConflict(ctx context.Context, e *models.Event, deleted bool) error {
+	var count int
+	query := "SELECT COUNT(*) FROM events WHERE id = ? AND (deleted_at <> 0) = ?"
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	if err := s.db.GetContext(ctx, &count, query, e.ID, deleted); err != nil {
+		return fmt.Errorf("%w: unexpected error -> %v", models.ErrDataError, err)
+	} else if count > 0 {
+		return fmt.Errorf("%w: event %s is not at version %d", models.ErrConflict, e.ID, e.Version)
+	}
+	return models.ErrNotFound
+}
+
+// Delete moves the event having its version into the trash, a zero version deletes any
+func (s *SQLiteStorage) Delete(ctx context.Context, e *models.Event) error {
+	query := `UPDATE events SET deleted_at = ?3, updated_at = ?3, version = version + 1
+			WHERE id = ?1 AND deleted_at = 0 AND (?2 = 0 OR version = ?2)`
+	return s.changeState(ctx, e, false, query, e.ID, e.Version, nanos(time.Now()))
+}
+
+// GetDeleted returns the event from the trash
+func (s *SQLiteStorage) GetDeleted(ctx context.Context, id string) (*models.Event, error) {
+	query := "SELECT " + eventColumns + " FROM events WHERE id = ? AND deleted_at <> 0"
+	events, err := s.queryEvents(ctx, query, id)
+	if err != nil {
+		return nil, err
+	} else if len(events) == 0 {
+		return nil, models.ErrNotFound
+	}
+	return events[0], nil
+}
+
+// ListDeleted returns the events in the trash, the last deleted go first, an empty owner lists them all
+func (s *SQLiteStorage) ListDeleted(ctx context.Context, owner string) ([]*models.Event, error) {
+	query := "SELECT " + eventColumns + ` FROM events
+				WHERE deleted_at <> 0 AND (?1 = '' OR owner = ?1) ORDER BY deleted_at DESC, id`
+	return s.queryEvents(ctx, query, owner)
+}
+
+// Restore brings the event having its version back from the trash, a zero version restores any
+func (s *SQLiteStorage) Restore(ctx context.Context, e *models.Event) error {
+	query := `UPDATE events SET deleted_at = 0, updated_at = ?3, version = version + 1
+			WHERE id = ?1 AND deleted_at <> 0 AND (?2 = 0 OR version = ?2)`
+	return s.changeState(ctx, e, true, query, e.ID, e.Version, nanos(time.Now()))
+}
+
+// changeState runs the statement changing the event in a transaction, deleted tells whether it changes
+// an event of the trash
+func (s *SQLiteStorage) changeState(
+	ctx context.Context, e *models.Event, deleted bool, query string, args ...interface{},
+) error {
+	qctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	tx, err := s.db.BeginTxx(qctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: unexpected error -> %v", models.ErrDataError, err)
+	}
+	defer tx.Rollback() //nolint:errcheck // it fails after commit only
+	if r, err := tx.ExecContext(qctx, query, args...); err != nil {
+		return fmt.Errorf("%w: unexpected error -> %v", models.ErrDataError, err)
+	} else if rows, _ := r.RowsAffected(); rows == 0 {
+		tx.Rollback() //nolint:errcheck // it releases the connection missingOrConflict may need
+		return s.missingOrConflict(ctx, e, deleted)
+	}
+	if s.audit != nil {
+		after, err := s.storedState(qctx, tx, e.ID)
+		if err != nil {
+			return err
+		}
+		if err = s.appendAudit(qctx, tx, after); err != nil {
+			return err
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%w: unexpected error -> %v", models.ErrDataError, err)
+	}
+	return nil
+}
+
+// Purge removes the event having its version from the trash for good, a zero version purges any
+func (s *SQLiteStorage) Purge(ctx context.Context, e *models.Event) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: unexpected error -> %v", models.ErrDataError, err)
+	}
+	defer tx.Rollback() //nolint:errcheck // it fails after commit only
+	var version int64
+	query := "SELECT version FROM events WHERE id = ? AND deleted_at <> 0"
+	if err = tx.GetContext(ctx, &version, query, e.ID); errors.Is(err, sql.ErrNoRows) {
+		return models.ErrNotFound
+	} else if err != nil {
+		return fmt.Errorf("%w: unexpected error -> %v", models.ErrDataError, err)
+	} else if e.Version != 0 && e.Version != version {
+		return fmt.Errorf("%w: event %s is not at version %d", models.ErrConflict, e.ID, e.Version)
+	}
+	// attendees are deleted explicitly as foreign keys are only enforced when the connection enables them
+	if _, err = tx.ExecContext(ctx, "DELETE FROM attendees WHERE event_id = ?", e.ID); err != nil {
+		return fmt.Errorf("%w: unexpected error -> %v", models.ErrDataError, err)
+	}
+	if _, err = tx.ExecContext(ctx, "DELETE FROM events WHERE id = ?", e.ID); err != nil {
+		return fmt.Errorf("%w: unexpected error -> %v", models.ErrDataError, err)
+	}
+	if err = s.appendAudit(ctx, tx, nil); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%w: unexpected error -> %v", models.ErrDataError, err)
+	}
+	return nil
+}
+
+// PurgeDeleted removes the events deleted before the time from the trash for good
+func (s *SQLiteStorage) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%w: purge failed -> %v", models.ErrDataError, err)
+	}
+	defer tx.Rollback() //nolint:errcheck // it fails after commit only
+	condition := "deleted_at <> 0 AND deleted_at < ?"
+	query := "DELETE FROM attendees WHERE event_id IN (SELECT id FROM events WHERE " + condition + ")"
+	if _, err = tx.ExecContext(ctx, query, nanos(before)); err != nil {
+		return 0, fmt.Errorf("%w: purge failed -> %v", models.ErrDataError, err)
+	}
+	r, err := tx.ExecContext(ctx, "DELETE FROM events WHERE "+condition, nanos(before))
+	if err != nil {
+		return 0, fmt.Errorf("%w: purge failed -> %v", models.ErrDataError, err)
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%w: purge failed -> %v", models.ErrDataError, err)
+	}
+	rows, _ := r.RowsAffected()
+	return int(rows), nil
+}
+
+func (s *SQLiteStorage) queryEvents(ctx context.Context, query string, args ...interface{}) ([]*models.Event, error) {
+	qctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	rows, err := s.db.QueryxContext(qctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unexpected error -> %v", models.ErrDataError, err)
+	}
+	var results []*models.Event
+	defer rows.Close()
+	for rows.Next() {
+		dbEvent := sqlEvent{}
+		if err := rows.StructScan(&dbEvent); err != nil {
+			return nil, fmt.Errorf("%w: unexpected error -> %v", models.ErrDataError, err)
+		}
+		if m, err := dbEvent.asModel(); err == nil {
+			results = append(results, m)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: unexpected error -> %v", models.ErrDataError, err)
+	}
+	// the pool has a single connection which the attendees query needs
+	rows.Close()
+	if err := s.loadAttendees(ctx, results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// windowCondition matches events overlapping [lBound, uBound) and recurring series which may do so,
+// all-day events are matched against the window as floating time
+func windowCondition(lBound time.Time, uBound time.Time) (string, []interface{}) {
+	flBound, fuBound := nanos(models.Floating(lBound)), nanos(models.Floating(uBound))
+	lb, ub := nanos(lBound), nanos(uBound)
+	condition := `e.deleted_at = 0
+					AND ((e.rrule = '' AND NOT e.all_day AND e.start_at < ? AND (e.end_at > ? OR e.start_at >= ?))
+					OR (e.rrule = '' AND e.all_day AND e.start_at < ? AND e.end_at > ?)
+					OR (e.rrule <> '' AND e.start_at < MAX(?, ?)))`
+	return condition, []interface{}{ub, lb, lb, fuBound, flBound, ub, fuBound}
+}
+
+// getEventList returns events and occurrences of recurring events overlapping [lBound, uBound),
+// no owners match events of any owner.
+func (s *SQLiteStorage) getEventList(
+	ctx context.Context, owners []string, lBound time.Time, uBound time.Time,
+) ([]*models.Event, error) {
+	condition, args := windowCondition(lBound, uBound)
+	query := "SELECT " + eventColumns + " FROM events AS e WHERE " + condition
+	if len(owners) > 0 {
+		query += " AND e.owner IN (?)"
+		args = append(args, owners)
+	}
+	query, args, err := sqlx.In(query+" ORDER BY e.start_at", args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unexpected error -> %v", models.ErrDataError, err)
+	}
+	events, err := s.queryEvents(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	var results []*models.Event
+	for _, e := range events {
+		results = append(results, e.Occurrences(lBound, uBound)...)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].StartsAt.Before(results[j].StartsAt) })
+	return results, nil
+}
+
+func (s *SQLiteStorage) GetDayList(ctx context.Context, d time.Time) ([]*models.Event, error) {
+	lBound, uBound := models.DayWindow(d)
+	return s.getEventList(ctx, nil, lBound, uBound)
+}
+
+func (s *SQLiteStorage) GetWeekList(
+	ctx context.Context, d time.Time, weekStart time.Weekday,
+) ([]*models.Event, error) {
+	lBound, uBound := models.WeekWindow(d, weekStart)
+	return s.getEventList(ctx, nil, lBound, uBound)
+}
+
+func (s *SQLiteStorage) GetMonthList(ctx context.Context, d time.Time) ([]*models.Event, error) {
+	lBound, uBound := models.MonthWindow(d)
+	return s.getEventList(ctx, nil, lBound, uBound)
+}
+
+func (s *SQLiteStorage) IsBusy(ctx context.Context, d1, d2 time.Time) (bool, error) {
+	return s.isBusy(ctx, "", d1, d2, "")
+}
+
+func (s *SQLiteStorage) GetDayListByOwner(ctx context.Context, owner string, d time.Time) ([]*models.Event, error) {
+	lBound, uBound := models.DayWindow(d)
+	return s.getEventList(ctx, []string{owner}, lBound, uBound)
+}
+
+func (s *SQLiteStorage) GetWeekListByOwner(
+	ctx context.Context, owner string, d time.Time, weekStart time.Weekday,
+) ([]*models.Event, error) {
+	lBound, uBound := models.WeekWindow(d, weekStart)
+	return s.getEventList(ctx, []string{owner}, lBound, uBound)
+}
+
+func (s *SQLiteStorage) GetMonthListByOwner(
+	ctx context.Context, owner string, d time.Time,
+) ([]*models.Event, error) {
+	lBound, uBound := models.MonthWindow(d)
+	return s.getEventList(ctx, []string{owner}, lBound, uBound)
+}
+
+func (s *SQLiteStorage) IsOwnerBusy(
+	ctx context.Context, owner string, d1, d2 time.Time, exceptID string,
+) (bool, error) {
+	return s.isBusy(ctx, owner, d1, d2, exceptID)
+}
+
+// GetRangeListByOwners returns events of the owners and occurrences of their recurring events
+// overlapping [lBound, uBound) with a single query
+func (s *SQLiteStorage) GetRangeListByOwners(
+	ctx context.Context, owners []string, lBound, uBound time.Time,
+) ([]*models.Event, error) {
+	if len(owners) == 0 {
+		return nil, nil
+	}
+	return s.getEventList(ctx, owners, lBound, uBound)
+}
+
+// GetRangePage returns up to size events and occurrences of recurring events overlapping [lBound, uBound)
+// which follow the cursor, an empty owner lists events of all owners. Single events are paged by the query
+// whereas recurring series are expanded and merged with them.
+func (s *SQLiteStorage) GetRangePage(
+	ctx context.Context, owner string, lBound, uBound time.Time, after *models.Cursor, size int,
+) (models.Page, error) {
+	flBound, fuBound := nanos(models.Floating(lBound)), nanos(models.Floating(uBound))
+	query := "SELECT " + eventColumns + ` FROM events AS e
+				WHERE e.deleted_at = 0
+					AND ((e.rrule = '' AND NOT e.all_day AND e.start_at < ? AND (e.end_at > ? OR e.start_at >= ?))
+					OR (e.rrule = '' AND e.all_day AND e.start_at < ? AND e.end_at > ?))`
+	args := []interface{}{nanos(uBound), nanos(lBound), nanos(lBound), fuBound, flBound}
+	if owner != "" {
+		query += " AND e.owner = ?"
+		args = append(args, owner)
+	}
+	if after != nil {
+		query += " AND (e.start_at, e.id) > (?, ?)"
+		args = append(args, nanos(after.StartsAt), after.ID)
+	}
+	query += " ORDER BY e.start_at, e.id LIMIT ?"
+	args = append(args, size+1)
+	events, err := s.queryEvents(ctx, query, args...)
+	if err != nil {
+		return models.Page{}, err
+	}
+
+	query = "SELECT " + eventColumns + ` FROM events AS e
+				WHERE e.deleted_at = 0 AND e.rrule <> '' AND e.start_at < MAX(?1, ?2)
+					AND (?3 = '' OR e.owner = ?3)`
+	series, err := s.queryEvents(ctx, query, nanos(uBound), fuBound, owner)
+	if err != nil {
+		return models.Page{}, err
+	}
+	for _, e := range series {
+		events = append(events, e.Occurrences(lBound, uBound)...)
+	}
+	return models.Paginate(events, after, size), nil
+}
+
+// Search returns events having all the words of the search text ordered by start, each word is matched
+// against the terms column and recurring events are expanded into occurrences when the search has a date range
+func (s *SQLiteStorage) Search(ctx context.Context, q models.SearchQuery) ([]*models.Event, error) {
+	terms := models.SearchTerms(q.Text)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	// the terms have letters and digits only, so they hold no wildcards of LIKE
+	query := "SELECT " + eventColumns + " FROM events AS e WHERE e.deleted_at = 0"
+	var args []interface{}
+	for _, term := range terms {
+		query += " AND e.terms LIKE ?"
+		args = append(args, "% "+term+" %")
+	}
+	if q.Owner != "" {
+		query += " AND e.owner = ?"
+		args = append(args, q.Owner)
+	}
+	if !q.HasRange() {
+		query += " ORDER BY e.start_at, e.id LIMIT ?"
+		return s.queryEvents(ctx, query, append(args, q.Limit)...)
+	}
+
+	condition, windowArgs := windowCondition(q.From, q.To)
+	query += " AND " + condition
+	events, err := s.queryEvents(ctx, query, append(args, windowArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	var results []*models.Event
+	for _, e := range events {
+		results = append(results, e.Occurrences(q.From, q.To)...)
+	}
+	models.SortListing(results)
+	if len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+	return results, nil
+}
+
+// GetListByOwner returns all events of the owner, recurring events are not expanded
+func (s *SQLiteStorage) GetListByOwner(ctx context.Context, owner string) ([]*models.Event, error) {
+	query := "SELECT " + eventColumns + " FROM events WHERE owner = ? AND deleted_at = 0 ORDER BY start_at"
+	return s.queryEvents(ctx, query, owner)
+}
+
+// isBusy checks overlaps with timed events, all-day events do not make the time busy
+func (s *SQLiteStorage) isBusy(ctx context.Context, owner string, d1, d2 time.Time, exceptID string) (bool, error) {
+	if !d1.Before(d2) {
+		return false, fmt.Errorf("%w: start must be before end", models.ErrValueError)
+	}
+	var overlapCount int
+	query := `SELECT COUNT(*) FROM events AS e
+				WHERE e.deleted_at = 0 AND e.rrule = '' AND NOT e.all_day
+					AND e.start_at < ?1 AND (e.end_at > ?2 OR e.start_at >= ?2)
+					AND (?3 = '' OR e.owner = ?3) AND e.id <> ?4`
+	qctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	if err := s.db.GetContext(qctx, &overlapCount, query, nanos(d2), nanos(d1), owner, exceptID); err != nil {
+		return false, fmt.Errorf("%w: unexpected error -> %v", models.ErrDataError, err)
+	}
+	if overlapCount > 0 {
+		return true, nil
+	}
+
+	query = "SELECT " + eventColumns + ` FROM events AS e
+				WHERE e.deleted_at = 0 AND e.rrule <> '' AND NOT e.all_day AND e.start_at < ?1
+					AND (?2 = '' OR e.owner = ?2) AND e.id <> ?3`
+	series, err := s.queryEvents(ctx, query, nanos(d2), owner, exceptID)
+	if err != nil {
+		return false, err
+	}
+	for _, e := range series {
+		if e.Overlaps(d1, d2) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// NewSQLiteStorage opens the database file at path creating it when missing and upgrades its schema,
+// the file is used by a single connection as sqlite serializes writes anyway
+func NewSQLiteStorage(path string, queryTimeout time.Duration) (models.EventsRepo, error) {
+	if path == "" {
+		return nil, fmt.Errorf("%w: sqlite storage needs a file path", models.ErrValueError)
+	}
+	db, err := sqlx.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite storage %s was not opened -> %w", path, err)
+	}
+	db.SetMaxOpenConns(1)
+	if _, err = db.Exec("PRAGMA busy_timeout = 5000"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("sqlite storage %s was not opened -> %w", path, err)
+	}
+	if err = migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("sqlite storage %s was not opened -> %w", path, err)
+	}
+	return &SQLiteStorage{db: db, queryTimeout: queryTimeout}, nil
+}
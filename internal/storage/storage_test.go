@@ -1,16 +1,236 @@
 package storage
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/VladNF/calendar/internal/common"
 	"github.com/VladNF/calendar/internal/models"
 	"github.com/stretchr/testify/require"
 )
 
 func TestStorage(t *testing.T) {
-	eventsRepo, _ := NewStorage("in-memory")
+	dir := t.TempDir()
+	confs := map[string]common.StorageConf{
+		"in-memory": {Kind: "in-memory"},
+		"snapshots": {Kind: "in-memory", Path: filepath.Join(dir, "calendar.json"), SnapshotInterval: time.Millisecond},
+		"sqlite":    {Kind: "sqlite", Path: filepath.Join(dir, "calendar.db")},
+	}
+	for name, conf := range confs {
+		eventsRepo, err := NewStorage(conf)
+		require.NoError(t, err)
+		t.Run(name, func(t *testing.T) {
+			testStorage(t, eventsRepo)
+		})
+	}
+}
+
+func TestSQLiteReopen(t *testing.T) {
+	ctx := context.Background()
+	conf := common.StorageConf{Kind: "sqlite", Path: filepath.Join(t.TempDir(), "calendar.db")}
+	eventsRepo, err := NewStorage(conf)
+	require.NoError(t, err)
+	start := time.Date(2021, 9, 6, 10, 0, 0, 0, time.UTC)
+	event, _ := models.NewEvent("", "Kept", start, start.Add(time.Hour), "keeper")
+	event.Attendees = []models.Attendee{{UserID: "guest", Status: models.Accepted}}
+	require.NoError(t, eventsRepo.Create(ctx, event))
+
+	eventsRepo, err = NewStorage(conf)
+	require.NoError(t, err)
+	kept, err := eventsRepo.Get(ctx, event.ID)
+	require.NoError(t, err)
+	require.Equal(t, event.Title, kept.Title)
+	require.True(t, event.StartsAt.Equal(kept.StartsAt))
+	require.Equal(t, event.Attendees, kept.Attendees)
+
+	_, err = NewStorage(common.StorageConf{Kind: "sqlite"})
+	require.ErrorIs(t, err, models.ErrValueError)
+}
 
+func TestMemoryReopen(t *testing.T) {
+	ctx := context.Background()
+	conf := common.StorageConf{Kind: "in-memory", Path: filepath.Join(t.TempDir(), "calendar.json")}
+	eventsRepo, err := NewStorage(conf)
+	require.NoError(t, err)
+	start := time.Date(2021, 9, 13, 9, 0, 0, 0, time.UTC)
+	standup, _ := models.NewEvent("", "Standup", start, start.Add(15*time.Minute), "keeper")
+	standup.Recurrence, err = models.ParseRRule("FREQ=DAILY;COUNT=5")
+	require.NoError(t, err)
+	standup.Recurrence.ExDates = []time.Time{start.AddDate(0, 0, 2)}
+	standup.Timezone, err = models.LoadTimezone("Europe/Berlin")
+	require.NoError(t, err)
+	standup.Attendees = []models.Attendee{{UserID: "guest", Status: models.Accepted}}
+	holiday, _ := models.NewAllDayEvent("", "Holiday", start, start, "keeper")
+	dropped, _ := models.NewEvent("", "Dropped", start, start.Add(time.Hour), "keeper")
+	for _, e := range []*models.Event{standup, holiday, dropped} {
+		require.NoError(t, eventsRepo.Create(ctx, e))
+	}
+	require.NoError(t, eventsRepo.Delete(ctx, dropped))
+
+	// a write cut short by a crash is dropped
+	log, err := os.OpenFile(conf.Path+".log", os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = log.WriteString(`{"op":"delete","id":"` + standup.ID)
+	require.NoError(t, err)
+	require.NoError(t, log.Close())
+
+	for i := 0; i < 2; i++ {
+		eventsRepo, err = NewStorage(conf)
+		require.NoError(t, err)
+		events, err := eventsRepo.GetListByOwner(ctx, "keeper")
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		kept, err := eventsRepo.Get(ctx, standup.ID)
+		require.NoError(t, err)
+		require.Equal(t, standup.Recurrence.String(), kept.Recurrence.String())
+		require.Equal(t, standup.Recurrence.ExDates, kept.Recurrence.ExDates)
+		require.Equal(t, "Europe/Berlin", kept.Timezone.String())
+		require.Equal(t, standup.Attendees, kept.Attendees)
+		kept, err = eventsRepo.Get(ctx, holiday.ID)
+		require.NoError(t, err)
+		require.True(t, kept.AllDay)
+		_, err = eventsRepo.Get(ctx, dropped.ID)
+		require.ErrorIs(t, err, models.ErrNotFound)
+		kept, err = eventsRepo.GetDeleted(ctx, dropped.ID)
+		require.NoError(t, err)
+		require.True(t, kept.IsDeleted())
+	}
+}
+
+func TestAudit(t *testing.T) {
+	ctx := context.Background()
+	confs := map[string]common.StorageConf{
+		"in-memory": {Kind: "in-memory", AuditCapacity: 4},
+		"sqlite":    {Kind: "sqlite", Path: filepath.Join(t.TempDir(), "calendar.db")},
+	}
+	for name, conf := range confs {
+		eventsRepo, err := NewStorage(conf)
+		require.NoError(t, err)
+		audit, err := NewAuditStorage(conf, eventsRepo)
+		require.NoError(t, err)
+		t.Run(name, func(t *testing.T) {
+			start := time.Date(2021, 9, 20, 9, 0, 0, 0, time.UTC)
+			created, _ := models.NewEvent("", "Standup", start, start.Add(15*time.Minute), "auditor")
+			created.Recurrence, err = models.ParseRRule("FREQ=DAILY;COUNT=5")
+			require.NoError(t, err)
+			created.Timezone, err = models.LoadTimezone("Europe/Berlin")
+			require.NoError(t, err)
+			created.Attendees = []models.Attendee{{UserID: "guest", Status: models.Accepted}}
+			created.Version = 1
+			updated := *created
+			updated.Title, updated.Version = "Daily", 2
+			other, _ := models.NewEvent("", "Other", start, start.Add(time.Hour), "auditor")
+
+			at := time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC)
+			records := []*models.AuditRecord{
+				{EventID: created.ID, Actor: "alice", At: at, Op: models.AuditCreate, After: created},
+				{EventID: other.ID, At: at, Op: models.AuditCreate, After: other},
+				{
+					EventID: created.ID, Actor: "bob", At: at.Add(time.Minute), Op: models.AuditUpdate,
+					Before: created, After: &updated,
+				},
+			}
+			for _, r := range records {
+				require.NoError(t, audit.Append(ctx, r))
+			}
+			// the records keep the events as they were appended
+			updated.Title = "changed after the record"
+
+			history, err := audit.History(ctx, created.ID)
+			require.NoError(t, err)
+			require.Len(t, history, 2)
+			require.Equal(t, "alice", history[0].Actor)
+			require.Equal(t, models.AuditCreate, history[0].Op)
+			require.True(t, at.Equal(history[0].At))
+			require.Nil(t, history[0].Before)
+			require.Equal(t, "Standup", history[0].After.Title)
+			require.Equal(t, created.Recurrence.String(), history[0].After.Recurrence.String())
+			require.Equal(t, "Europe/Berlin", history[0].After.Timezone.String())
+			require.Equal(t, created.Attendees, history[0].After.Attendees)
+			require.Equal(t, models.AuditUpdate, history[1].Op)
+			require.Equal(t, "Standup", history[1].Before.Title)
+			require.Equal(t, "Daily", history[1].After.Title)
+			require.EqualValues(t, 2, history[1].After.Version)
+
+			history, err = audit.History(ctx, "never-stored")
+			require.NoError(t, err)
+			require.Empty(t, history)
+		})
+	}
+
+	// the ring buffer of in-memory storage overwrites the oldest records
+	conf := confs["in-memory"]
+	audit, err := NewAuditStorage(conf, nil)
+	require.NoError(t, err)
+	event, _ := models.NewEvent("", "Busy", time.Now(), time.Now().Add(time.Hour), "auditor")
+	for i := 0; i < conf.AuditCapacity+2; i++ {
+		event.Version = int64(i + 1)
+		require.NoError(t, audit.Append(ctx, &models.AuditRecord{EventID: event.ID, Op: models.AuditUpdate, After: event}))
+	}
+	history, err := audit.History(ctx, event.ID)
+	require.NoError(t, err)
+	require.Len(t, history, conf.AuditCapacity)
+	for i, r := range history {
+		require.EqualValues(t, i+3, r.After.Version)
+	}
+}
+
+func TestAuditedChanges(t *testing.T) {
+	ctx := context.Background()
+	eventsRepo, err := NewStorage(common.StorageConf{Kind: "sqlite", Path: filepath.Join(t.TempDir(), "calendar.db")})
+	require.NoError(t, err)
+	audit := eventsRepo.(models.AuditRepo)
+	audited := eventsRepo.(models.AuditedRepo)
+	start := time.Date(2021, 9, 20, 9, 0, 0, 0, time.UTC)
+	event, _ := models.NewEvent("", "Audited", start, start.Add(time.Hour), "auditor")
+	event.Attendees = []models.Attendee{{UserID: "guest", Status: models.Accepted}}
+	record := func(op models.AuditOp, before *models.Event) *models.AuditRecord {
+		return &models.AuditRecord{EventID: event.ID, Actor: "alice", At: time.Now().UTC(), Op: op, Before: before}
+	}
+
+	// the records get the events as the changes stored them
+	require.NoError(t, audited.WithAudit(record(models.AuditCreate, nil)).Create(ctx, event))
+	created := *event
+	event.Title = "Renamed"
+	require.NoError(t, audited.WithAudit(record(models.AuditUpdate, &created)).Update(ctx, event))
+	// a change which fails appends no record
+	stale := *event
+	stale.Version = 1
+	err = audited.WithAudit(record(models.AuditUpdate, &created)).Update(ctx, &stale)
+	require.ErrorIs(t, err, models.ErrConflict)
+	require.NoError(t, audited.WithAudit(record(models.AuditDelete, event)).Delete(ctx, event))
+	deleted, err := eventsRepo.GetDeleted(ctx, event.ID)
+	require.NoError(t, err)
+	require.NoError(t, audited.WithAudit(record(models.AuditPurge, deleted)).Purge(ctx, deleted))
+
+	history, err := audit.History(ctx, event.ID)
+	require.NoError(t, err)
+	ops := make([]models.AuditOp, 0, len(history))
+	for _, r := range history {
+		require.Equal(t, "alice", r.Actor)
+		ops = append(ops, r.Op)
+	}
+	require.Equal(t, []models.AuditOp{models.AuditCreate, models.AuditUpdate, models.AuditDelete, models.AuditPurge}, ops)
+	require.EqualValues(t, 1, history[0].After.Version)
+	require.Equal(t, "Renamed", history[1].After.Title)
+	require.EqualValues(t, 2, history[1].After.Version)
+	require.EqualValues(t, 3, history[2].After.Version)
+	require.True(t, history[2].After.IsDeleted())
+	require.Equal(t, event.Attendees, history[2].After.Attendees)
+	require.Nil(t, history[3].After)
+
+	// storages without an audit record append nothing
+	other, _ := models.NewEvent("", "Unaudited", start, start.Add(time.Hour), "auditor")
+	require.NoError(t, eventsRepo.Create(ctx, other))
+	history, err = audit.History(ctx, other.ID)
+	require.NoError(t, err)
+	require.Empty(t, history)
+}
+
+func testStorage(t *testing.T, eventsRepo models.EventsRepo) {
 	t.Run("basic test", func(t *testing.T) {
 		testBasicOperations(t, eventsRepo)
 	})
@@ -26,9 +246,516 @@ func TestStorage(t *testing.T) {
 	t.Run("month view test", func(t *testing.T) {
 		testMonthViewQuery(t, eventsRepo)
 	})
+
+	t.Run("recurring events test", func(t *testing.T) {
+		testRecurringQuery(t, eventsRepo)
+	})
+
+	t.Run("owner scope test", func(t *testing.T) {
+		testOwnerScope(t, eventsRepo)
+	})
+
+	t.Run("multi-day events test", func(t *testing.T) {
+		testMultiDayQuery(t, eventsRepo)
+	})
+
+	t.Run("timezone test", func(t *testing.T) {
+		testTimezoneQuery(t, eventsRepo)
+	})
+
+	t.Run("week start test", func(t *testing.T) {
+		testWeekStart(t, eventsRepo)
+	})
+
+	t.Run("range page test", func(t *testing.T) {
+		testRangePage(t, eventsRepo)
+	})
+
+	t.Run("search test", func(t *testing.T) {
+		testSearch(t, eventsRepo)
+	})
+
+	t.Run("version test", func(t *testing.T) {
+		testVersions(t, eventsRepo)
+	})
+
+	t.Run("trash test", func(t *testing.T) {
+		testTrash(t, eventsRepo)
+	})
+}
+
+func testTrash(t *testing.T, eventsRepo models.EventsRepo) {
+	ctx := context.Background()
+	start := time.Date(2021, 11, 8, 9, 0, 0, 0, time.UTC)
+	event, _ := models.NewEvent("", "Trashed", start, start.Add(time.Hour), "trasher")
+	event.Attendees = []models.Attendee{{UserID: "guest", Status: models.Accepted}}
+	require.NoError(t, eventsRepo.Create(ctx, event))
+	require.NoError(t, eventsRepo.Delete(ctx, event))
+
+	// deleted events are missing from reads but keep their IDs taken
+	_, err := eventsRepo.Get(ctx, event.ID)
+	require.ErrorIs(t, err, models.ErrNotFound)
+	list, err := eventsRepo.GetDayListByOwner(ctx, "trasher", start)
+	require.NoError(t, err)
+	require.Len(t, list, 0)
+	list, err = eventsRepo.GetListByOwner(ctx, "trasher")
+	require.NoError(t, err)
+	require.Len(t, list, 0)
+	list, err = eventsRepo.Search(ctx, models.SearchQuery{Text: "trashed", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, list, 0)
+	busy, err := eventsRepo.IsOwnerBusy(ctx, "trasher", start, start.Add(time.Hour), "")
+	require.NoError(t, err)
+	require.False(t, busy)
+	duplicate, _ := models.NewEvent(event.ID, "Duplicate", start, start.Add(time.Hour), "trasher")
+	require.ErrorIs(t, eventsRepo.Create(ctx, duplicate), models.ErrAlreadyExists)
+	require.ErrorIs(t, eventsRepo.Update(ctx, event), models.ErrNotFound)
+	require.ErrorIs(t, eventsRepo.Delete(ctx, event), models.ErrNotFound)
+
+	deleted, err := eventsRepo.GetDeleted(ctx, event.ID)
+	require.NoError(t, err)
+	require.True(t, deleted.IsDeleted())
+	require.EqualValues(t, 2, deleted.Version)
+	require.Equal(t, event.Attendees, deleted.Attendees)
+	trash, err := eventsRepo.ListDeleted(ctx, "trasher")
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	require.Equal(t, event.ID, trash[0].ID)
+
+	stale := *deleted
+	stale.Version = 1
+	require.ErrorIs(t, eventsRepo.Restore(ctx, &stale), models.ErrConflict)
+	require.ErrorIs(t, eventsRepo.Purge(ctx, &stale), models.ErrConflict)
+	require.NoError(t, eventsRepo.Restore(ctx, deleted))
+	restored, err := eventsRepo.Get(ctx, event.ID)
+	require.NoError(t, err)
+	require.False(t, restored.IsDeleted())
+	require.EqualValues(t, 3, restored.Version)
+	require.ErrorIs(t, eventsRepo.Restore(ctx, restored), models.ErrNotFound)
+	require.ErrorIs(t, eventsRepo.Purge(ctx, restored), models.ErrNotFound)
+
+	// purged events are gone for good and free their IDs
+	require.NoError(t, eventsRepo.Delete(ctx, restored))
+	purged, err := eventsRepo.PurgeDeleted(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Zero(t, purged)
+	require.NoError(t, eventsRepo.Purge(ctx, &models.Event{ID: event.ID}))
+	_, err = eventsRepo.GetDeleted(ctx, event.ID)
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	old, _ := models.NewEvent("", "Old", start, start.Add(time.Hour), "trasher")
+	require.NoError(t, eventsRepo.Create(ctx, old))
+	require.NoError(t, eventsRepo.Delete(ctx, old))
+	purged, err = eventsRepo.PurgeDeleted(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, purged, 1)
+	trash, err = eventsRepo.ListDeleted(ctx, "trasher")
+	require.NoError(t, err)
+	require.Len(t, trash, 0)
+	require.NoError(t, eventsRepo.Create(ctx, duplicate))
+	require.NoError(t, eventsRepo.Delete(ctx, duplicate))
+}
+
+func testVersions(t *testing.T, eventsRepo models.EventsRepo) {
+	ctx := context.Background()
+	start := time.Date(2021, 10, 4, 9, 0, 0, 0, time.UTC)
+	event, _ := models.NewEvent("", "Versioned", start, start.Add(time.Hour), "versioner")
+	require.NoError(t, eventsRepo.Create(ctx, event))
+	require.EqualValues(t, 1, event.Version)
+	require.False(t, event.UpdatedAt.IsZero())
+
+	first := *event
+	second := *event
+	first.Title = "First edit"
+	require.NoError(t, eventsRepo.Update(ctx, &first))
+	require.EqualValues(t, 2, first.Version)
+	second.Title = "Second edit"
+	require.ErrorIs(t, eventsRepo.Update(ctx, &second), models.ErrConflict)
+	require.ErrorIs(t, eventsRepo.Delete(ctx, &second), models.ErrConflict)
+
+	stored, err := eventsRepo.Get(ctx, event.ID)
+	require.NoError(t, err)
+	require.Equal(t, "First edit", stored.Title)
+	require.EqualValues(t, 2, stored.Version)
+
+	duplicate, _ := models.NewEvent(event.ID, "Duplicate", start, start.Add(time.Hour), "versioner")
+	require.ErrorIs(t, eventsRepo.Create(ctx, duplicate), models.ErrAlreadyExists)
+	require.NoError(t, eventsRepo.Delete(ctx, &first))
+
+	// the event is gone and can not be updated or deleted again
+	require.ErrorIs(t, eventsRepo.Update(ctx, &first), models.ErrNotFound)
+	require.ErrorIs(t, eventsRepo.Delete(ctx, &first), models.ErrNotFound)
+}
+
+func testSearch(t *testing.T, eventsRepo models.EventsRepo) {
+	ctx := context.Background()
+	require.Equal(t, []string{"q3", "budget", "review"}, models.SearchTerms("Q3 budget, budget-review!"))
+	empty := models.SearchQuery{Text: " ,. ", Limit: 1}
+	require.ErrorIs(t, empty.Validate(), models.ErrValueError)
+
+	start := time.Date(2021, 8, 2, 10, 0, 0, 0, time.UTC)
+	review, _ := models.NewEvent("", "Budget review", start, start.Add(time.Hour), "searcher")
+	review.Notes = "Q3 numbers"
+	lunch, _ := models.NewEvent("", "Team lunch", start.Add(2*time.Hour), start.Add(3*time.Hour), "searcher")
+	tomorrow := start.AddDate(0, 0, 1)
+	sync, _ := models.NewEvent("", "budget sync", tomorrow, tomorrow.Add(time.Hour), "searcher")
+	var err error
+	sync.Recurrence, err = models.ParseRRule("FREQ=WEEKLY;COUNT=3")
+	require.NoError(t, err)
+	other, _ := models.NewEvent("", "budget", start, start.Add(time.Hour), "stranger")
+	for _, e := range []*models.Event{review, lunch, sync, other} {
+		require.NoError(t, eventsRepo.Create(ctx, e))
+	}
+
+	list, err := eventsRepo.Search(ctx, models.SearchQuery{Text: "BUDGET", Owner: "searcher", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.Equal(t, review.ID, list[0].ID)
+	require.Equal(t, sync.ID, list[1].ID)
+
+	list, err = eventsRepo.Search(ctx, models.SearchQuery{Text: "numbers budget", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, review.ID, list[0].ID)
+
+	list, err = eventsRepo.Search(ctx, models.SearchQuery{Text: "budget", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+
+	// occurrences are listed within a date range
+	list, err = eventsRepo.Search(ctx, models.SearchQuery{
+		Text: "sync", Owner: "searcher", From: start, To: start.AddDate(0, 1, 0), Limit: 2,
+	})
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.True(t, start.AddDate(0, 0, 8).Equal(list[1].StartsAt))
+
+	// the index follows changes
+	dinner := *lunch
+	dinner.Title = "Team dinner"
+	require.NoError(t, eventsRepo.Update(ctx, &dinner))
+	list, err = eventsRepo.Search(ctx, models.SearchQuery{Text: "lunch", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, list, 0)
+	list, err = eventsRepo.Search(ctx, models.SearchQuery{Text: "dinner", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	for _, e := range []*models.Event{review, &dinner, sync, other} {
+		require.NoError(t, eventsRepo.Delete(ctx, e))
+	}
+	list, err = eventsRepo.Search(ctx, models.SearchQuery{Text: "budget", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, list, 0)
+}
+
+func testRangePage(t *testing.T, eventsRepo models.EventsRepo) {
+	ctx := context.Background()
+	_, err := models.ParseCursor("not a token")
+	require.ErrorIs(t, err, models.ErrValueError)
+
+	start := time.Date(2021, 7, 5, 9, 0, 0, 0, time.UTC)
+	daily, _ := models.NewEvent("", "daily", start, start.Add(time.Hour), "pager")
+	daily.Recurrence, err = models.ParseRRule("FREQ=DAILY;COUNT=5")
+	require.NoError(t, err)
+	require.NoError(t, eventsRepo.Create(ctx, daily))
+	// events at the same time as the occurrences are ordered by ID
+	var singles []*models.Event
+	for i := 0; i < 3; i++ {
+		e, _ := models.NewEvent("", "single", start.AddDate(0, 0, i), start.AddDate(0, 0, i).Add(time.Hour), "pager")
+		require.NoError(t, eventsRepo.Create(ctx, e))
+		singles = append(singles, e)
+	}
+	other, _ := models.NewEvent("", "other", start, start.Add(time.Hour), "stranger")
+	require.NoError(t, eventsRepo.Create(ctx, other))
+
+	var listed []*models.Event
+	var after *models.Cursor
+	pages := 0
+	for {
+		page, err := eventsRepo.GetRangePage(ctx, "pager", start, start.AddDate(0, 1, 0), after, 3)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(page.Events), 3)
+		listed = append(listed, page.Events...)
+		pages++
+		if page.NextToken == "" {
+			break
+		}
+		after, err = models.ParseCursor(page.NextToken)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 3, pages)
+	require.Len(t, listed, 8)
+	for i := 1; i < len(listed); i++ {
+		c := models.CursorOf(listed[i-1])
+		require.True(t, c.Precedes(listed[i]))
+	}
+
+	page, err := eventsRepo.GetRangePage(ctx, "", start, start.Add(time.Hour), nil, 10)
+	require.NoError(t, err)
+	require.Len(t, page.Events, 3)
+	require.Empty(t, page.NextToken)
+
+	require.NoError(t, eventsRepo.Delete(ctx, daily))
+	require.NoError(t, eventsRepo.Delete(ctx, other))
+	for _, e := range singles {
+		require.NoError(t, eventsRepo.Delete(ctx, e))
+	}
+}
+
+func testWeekStart(t *testing.T, eventsRepo models.EventsRepo) {
+	ctx := context.Background()
+	// 1 January 2021 is Friday, so ISO week 1 of 2021 starts on 4 January
+	start, err := models.ParseISOWeek("2021-W01", time.UTC)
+	require.NoError(t, err)
+	require.True(t, time.Date(2021, 1, 4, 0, 0, 0, 0, time.UTC).Equal(start))
+	start, err = models.ParseISOWeek("2020-W53", time.UTC)
+	require.NoError(t, err)
+	require.True(t, time.Date(2020, 12, 28, 0, 0, 0, 0, time.UTC).Equal(start))
+	_, err = models.ParseISOWeek("2021-W53", time.UTC)
+	require.ErrorIs(t, err, models.ErrValueError)
+	_, err = models.ParseISOWeek("2021-42", time.UTC)
+	require.ErrorIs(t, err, models.ErrValueError)
+
+	sunday := time.Date(2021, 4, 11, 10, 0, 0, 0, time.UTC)
+	event, _ := models.NewEvent("", "sunday", sunday, sunday.Add(time.Hour), "weeks")
+	require.NoError(t, eventsRepo.Create(ctx, event))
+
+	list, err := eventsRepo.GetWeekListByOwner(ctx, "weeks", sunday.AddDate(0, 0, 1), time.Sunday)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	list, err = eventsRepo.GetWeekListByOwner(ctx, "weeks", sunday.AddDate(0, 0, 1), time.Monday)
+	require.NoError(t, err)
+	require.Len(t, list, 0)
+	list, err = eventsRepo.GetWeekListByOwner(ctx, "weeks", sunday, time.Monday)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	require.NoError(t, eventsRepo.Delete(ctx, event))
+}
+
+func testTimezoneQuery(t *testing.T, eventsRepo models.EventsRepo) {
+	ctx := context.Background()
+	berlin, err := models.LoadTimezone("Europe/Berlin")
+	require.NoError(t, err)
+	tokyo, err := models.LoadTimezone("Asia/Tokyo")
+	require.NoError(t, err)
+	_, err = models.LoadTimezone("Mars/Olympus")
+	require.ErrorIs(t, err, models.ErrValueError)
+
+	// daylight saving time starts in Berlin on 28 March 2021
+	monday := time.Date(2021, 3, 22, 9, 0, 0, 0, berlin)
+	sync, _ := models.NewEvent("", "sync", monday, monday.Add(30*time.Minute), "tz")
+	sync.Timezone = berlin
+	sync.Recurrence, err = models.ParseRRule("FREQ=WEEKLY;COUNT=2")
+	require.NoError(t, err)
+	require.NoError(t, eventsRepo.Create(ctx, sync))
+
+	list, err := eventsRepo.GetDayListByOwner(ctx, "tz", time.Date(2021, 3, 29, 0, 0, 0, 0, berlin))
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.True(t, time.Date(2021, 3, 29, 7, 0, 0, 0, time.UTC).Equal(list[0].StartsAt))
+
+	// a late UTC event is on the next day in Tokyo
+	late := time.Date(2021, 3, 30, 23, 0, 0, 0, time.UTC)
+	call, _ := models.NewEvent("", "call", late, late.Add(30*time.Minute), "tz")
+	require.NoError(t, eventsRepo.Create(ctx, call))
+
+	list, err = eventsRepo.GetDayListByOwner(ctx, "tz", time.Date(2021, 3, 31, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, list, 0)
+	list, err = eventsRepo.GetDayListByOwner(ctx, "tz", time.Date(2021, 3, 31, 12, 0, 0, 0, tokyo))
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, call.ID, list[0].ID)
+
+	require.NoError(t, eventsRepo.Delete(ctx, sync))
+	require.NoError(t, eventsRepo.Delete(ctx, call))
+}
+
+func testMultiDayQuery(t *testing.T, eventsRepo models.EventsRepo) {
+	ctx := context.Background()
+	monday := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	_, err := models.NewEvent("", "backwards", monday, monday.Add(-time.Hour), "1")
+	require.ErrorIs(t, err, models.ErrValueError)
+
+	onCall, err := models.NewEvent("", "on-call", monday.Add(22*time.Hour), monday.Add(30*time.Hour), "1")
+	require.NoError(t, err)
+	wednesday, thursday := monday.AddDate(0, 0, 2), monday.AddDate(0, 0, 3)
+	conference, err := models.NewEvent("", "conference", monday.Add(9*time.Hour), wednesday.Add(18*time.Hour), "1")
+	require.NoError(t, err)
+	vacation, err := models.NewAllDayEvent("", "vacation", thursday.Add(15*time.Hour), thursday.AddDate(0, 0, 2), "1")
+	require.NoError(t, err)
+	require.True(t, thursday.Equal(vacation.StartsAt))
+	require.NoError(t, eventsRepo.Create(ctx, onCall))
+	require.NoError(t, eventsRepo.Create(ctx, conference))
+	require.NoError(t, eventsRepo.Create(ctx, vacation))
+
+	list, err := eventsRepo.GetDayList(ctx, monday)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.Equal(t, *conference, *list[0])
+	require.Equal(t, *onCall, *list[1])
+
+	list, err = eventsRepo.GetDayList(ctx, monday.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+
+	list, err = eventsRepo.GetDayList(ctx, monday.AddDate(0, 0, 2))
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, *conference, *list[0])
+
+	list, err = eventsRepo.GetWeekList(ctx, monday, time.Sunday)
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+
+	east, west := time.FixedZone("UTC+10", 10*60*60), time.FixedZone("UTC-8", -8*60*60)
+	list, err = eventsRepo.GetDayList(ctx, time.Date(2021, 3, 5, 0, 0, 0, 0, east))
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, *vacation, *list[0])
+
+	list, err = eventsRepo.GetDayList(ctx, time.Date(2021, 3, 5, 23, 0, 0, 0, west))
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, *vacation, *list[0])
+
+	list, err = eventsRepo.GetDayList(ctx, time.Date(2021, 3, 6, 0, 0, 0, 0, east))
+	require.NoError(t, err)
+	require.Len(t, list, 0)
+
+	busy, err := eventsRepo.IsOwnerBusy(ctx, "1", monday.AddDate(0, 0, 4), monday.AddDate(0, 0, 4).Add(time.Hour), "")
+	require.NoError(t, err)
+	require.False(t, busy)
+
+	busy, err = eventsRepo.IsOwnerBusy(ctx, "1", monday.Add(29*time.Hour), monday.Add(31*time.Hour), "")
+	require.NoError(t, err)
+	require.True(t, busy)
+
+	require.NoError(t, eventsRepo.Delete(ctx, onCall))
+	require.NoError(t, eventsRepo.Delete(ctx, conference))
+	require.NoError(t, eventsRepo.Delete(ctx, vacation))
+	list, err = eventsRepo.GetWeekList(ctx, monday, time.Sunday)
+	require.NoError(t, err)
+	require.Len(t, list, 0)
+}
+
+func testOwnerScope(t *testing.T, eventsRepo models.EventsRepo) {
+	ctx := context.Background()
+	start := time.Date(2021, 2, 1, 10, 0, 0, 0, time.UTC)
+	eventAlice, _ := models.NewEvent("", "alice", start, start.Add(time.Hour), "alice")
+	eventBob, _ := models.NewEvent("", "bob", start.Add(30*time.Minute), start.Add(2*time.Hour), "bob")
+	require.NoError(t, eventsRepo.Create(ctx, eventAlice))
+	require.NoError(t, eventsRepo.Create(ctx, eventBob))
+
+	list, err := eventsRepo.GetDayList(ctx, start)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+
+	list, err = eventsRepo.GetDayListByOwner(ctx, "alice", start)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, *eventAlice, *list[0])
+
+	list, err = eventsRepo.GetWeekListByOwner(ctx, "bob", start, time.Sunday)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, *eventBob, *list[0])
+
+	list, err = eventsRepo.GetMonthListByOwner(ctx, "carol", start)
+	require.NoError(t, err)
+	require.Len(t, list, 0)
+
+	list, err = eventsRepo.GetRangeListByOwners(ctx, []string{"alice", "bob", "carol"}, start, start.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.Equal(t, *eventAlice, *list[0])
+
+	// owners listed twice and empty owners do not add events
+	list, err = eventsRepo.GetRangeListByOwners(ctx, []string{"alice", "alice", ""}, start, start.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	list, err = eventsRepo.GetRangeListByOwners(ctx, []string{"bob"}, start.Add(-time.Hour), start.Add(20*time.Minute))
+	require.NoError(t, err)
+	require.Len(t, list, 0)
+
+	busy, err := eventsRepo.IsBusy(ctx, start.Add(90*time.Minute), start.Add(3*time.Hour))
+	require.NoError(t, err)
+	require.True(t, busy)
+
+	busy, err = eventsRepo.IsOwnerBusy(ctx, "alice", start.Add(90*time.Minute), start.Add(3*time.Hour), "")
+	require.NoError(t, err)
+	require.False(t, busy)
+
+	busy, err = eventsRepo.IsOwnerBusy(ctx, "alice", start.Add(-time.Hour), start.Add(3*time.Hour), "")
+	require.NoError(t, err)
+	require.True(t, busy)
+
+	busy, err = eventsRepo.IsOwnerBusy(ctx, "alice", start.Add(-time.Hour), start.Add(3*time.Hour), eventAlice.ID)
+	require.NoError(t, err)
+	require.False(t, busy)
+
+	_, err = eventsRepo.IsOwnerBusy(ctx, "alice", start, start, "")
+	require.ErrorIs(t, err, models.ErrValueError)
+
+	require.NoError(t, eventsRepo.Delete(ctx, eventAlice))
+	require.NoError(t, eventsRepo.Delete(ctx, eventBob))
+}
+
+func testRecurringQuery(t *testing.T, eventsRepo models.EventsRepo) {
+	ctx := context.Background()
+	_, err := models.ParseRRule("FREQ=HOURLY")
+	require.ErrorIs(t, err, models.ErrValueError)
+
+	monday := time.Date(2021, 1, 4, 10, 0, 0, 0, time.UTC)
+	standup, _ := models.NewEvent("", "standup", monday, monday.Add(15*time.Minute), "1")
+	standup.Recurrence, err = models.ParseRRule("FREQ=WEEKLY;BYDAY=MO,WE;COUNT=5")
+	require.NoError(t, err)
+	standup.Recurrence.ExDates = []time.Time{monday.AddDate(0, 0, 2)}
+	review, _ := models.NewEvent("", "review", monday, monday.Add(time.Hour), "1")
+	review.Recurrence, err = models.ParseRRule("FREQ=MONTHLY;BYDAY=-1FR")
+	require.NoError(t, err)
+	require.NoError(t, eventsRepo.Create(ctx, standup))
+	require.NoError(t, eventsRepo.Create(ctx, review))
+
+	list, err := eventsRepo.GetWeekList(ctx, monday, time.Sunday)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, standup.ID, list[0].ID)
+	require.True(t, monday.Equal(list[0].OccurrenceStart))
+
+	list, err = eventsRepo.GetMonthList(ctx, monday)
+	require.NoError(t, err)
+	require.Len(t, list, 5)
+	require.Equal(t, review.ID, list[4].ID)
+	require.True(t, time.Date(2021, 1, 29, 10, 0, 0, 0, time.UTC).Equal(list[4].StartsAt))
+
+	list, err = eventsRepo.GetDayList(ctx, monday.AddDate(0, 0, 9))
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.True(t, monday.AddDate(0, 0, 9).Equal(list[0].StartsAt))
+	require.True(t, monday.AddDate(0, 0, 9).Add(15*time.Minute).Equal(list[0].EndsAt))
+
+	list, err = eventsRepo.GetDayList(ctx, monday.AddDate(0, 0, 16))
+	require.NoError(t, err)
+	require.Len(t, list, 0)
+
+	list, err = eventsRepo.GetMonthList(ctx, monday.AddDate(0, 1, 0))
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.True(t, time.Date(2021, 2, 26, 10, 0, 0, 0, time.UTC).Equal(list[0].StartsAt))
+
+	require.NoError(t, eventsRepo.Delete(ctx, standup))
+	require.NoError(t, eventsRepo.Delete(ctx, review))
+	list, err = eventsRepo.GetMonthList(ctx, monday)
+	require.NoError(t, err)
+	require.Len(t, list, 0)
 }
 
 func testMonthViewQuery(t *testing.T, eventsRepo models.EventsRepo) {
+	ctx := context.Background()
 	ny2021 := time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC)
 
 	startAt, endAt := ny2021, ny2021.Add(time.Hour)
@@ -42,37 +769,38 @@ func testMonthViewQuery(t *testing.T, eventsRepo models.EventsRepo) {
 
 	startAt, endAt = startAt.AddDate(0, 0, 1), endAt.AddDate(0, 0, 1)
 	eventNextWeek, _ := models.NewEvent("", "title 4", startAt, endAt, "1")
-	require.NoError(t, eventsRepo.Put(eventNY3))
-	require.NoError(t, eventsRepo.Put(eventNY2))
-	require.NoError(t, eventsRepo.Put(eventNY1))
-	require.NoError(t, eventsRepo.Put(eventNextWeek))
+	require.NoError(t, eventsRepo.Create(ctx, eventNY3))
+	require.NoError(t, eventsRepo.Create(ctx, eventNY2))
+	require.NoError(t, eventsRepo.Create(ctx, eventNY1))
+	require.NoError(t, eventsRepo.Create(ctx, eventNextWeek))
 
-	list, err := eventsRepo.GetMonthList(time.Now())
+	list, err := eventsRepo.GetMonthList(ctx, time.Now())
 	require.NoError(t, err)
 	require.Len(t, list, 0)
 
-	list, err = eventsRepo.GetMonthList(ny2021)
+	list, err = eventsRepo.GetMonthList(ctx, ny2021)
 	require.NoError(t, err)
 	require.Len(t, list, 1)
 	require.Equal(t, *eventNY1, *list[0])
 
-	list, err = eventsRepo.GetMonthList(startAt)
+	list, err = eventsRepo.GetMonthList(ctx, startAt)
 	require.NoError(t, err)
 	require.Len(t, list, 3)
 	require.Equal(t, *eventNY2, *list[0])
 	require.Equal(t, *eventNY3, *list[1])
 	require.Equal(t, *eventNextWeek, *list[2])
 
-	require.NoError(t, eventsRepo.Delete(eventNY1))
-	list, err = eventsRepo.GetMonthList(ny2021)
+	require.NoError(t, eventsRepo.Delete(ctx, eventNY1))
+	list, err = eventsRepo.GetMonthList(ctx, ny2021)
 	require.NoError(t, err)
 	require.Len(t, list, 0)
-	require.NoError(t, eventsRepo.Delete(eventNY2))
-	require.NoError(t, eventsRepo.Delete(eventNY3))
-	require.NoError(t, eventsRepo.Delete(eventNextWeek))
+	require.NoError(t, eventsRepo.Delete(ctx, eventNY2))
+	require.NoError(t, eventsRepo.Delete(ctx, eventNY3))
+	require.NoError(t, eventsRepo.Delete(ctx, eventNextWeek))
 }
 
 func testWeekViewQuery(t *testing.T, eventsRepo models.EventsRepo) {
+	ctx := context.Background()
 	ny2021 := time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC)
 
 	startAt, endAt := ny2021, ny2021.Add(time.Hour)
@@ -86,90 +814,92 @@ func testWeekViewQuery(t *testing.T, eventsRepo models.EventsRepo) {
 
 	startAt, endAt = startAt.AddDate(0, 0, 1), endAt.AddDate(0, 0, 1)
 	eventNextWeek, _ := models.NewEvent("", "title 4", startAt, endAt, "1")
-	require.NoError(t, eventsRepo.Put(eventNY3))
-	require.NoError(t, eventsRepo.Put(eventNY2))
-	require.NoError(t, eventsRepo.Put(eventNY1))
-	require.NoError(t, eventsRepo.Put(eventNextWeek))
+	require.NoError(t, eventsRepo.Create(ctx, eventNY3))
+	require.NoError(t, eventsRepo.Create(ctx, eventNY2))
+	require.NoError(t, eventsRepo.Create(ctx, eventNY1))
+	require.NoError(t, eventsRepo.Create(ctx, eventNextWeek))
 
-	list, err := eventsRepo.GetWeekList(time.Now())
+	list, err := eventsRepo.GetWeekList(ctx, time.Now(), time.Sunday)
 	require.NoError(t, err)
 	require.Len(t, list, 0)
 
-	list, err = eventsRepo.GetWeekList(ny2021)
+	list, err = eventsRepo.GetWeekList(ctx, ny2021, time.Sunday)
 	require.NoError(t, err)
 	require.Len(t, list, 3)
 	require.Equal(t, *eventNY1, *list[0])
 	require.Equal(t, *eventNY2, *list[1])
 	require.Equal(t, *eventNY3, *list[2])
 
-	require.NoError(t, eventsRepo.Delete(eventNY1))
-	list, err = eventsRepo.GetWeekList(ny2021)
+	require.NoError(t, eventsRepo.Delete(ctx, eventNY1))
+	list, err = eventsRepo.GetWeekList(ctx, ny2021, time.Sunday)
 	require.NoError(t, err)
 	require.Len(t, list, 2)
-	require.NoError(t, eventsRepo.Delete(eventNY2))
-	require.NoError(t, eventsRepo.Delete(eventNY3))
-	require.NoError(t, eventsRepo.Delete(eventNextWeek))
+	require.NoError(t, eventsRepo.Delete(ctx, eventNY2))
+	require.NoError(t, eventsRepo.Delete(ctx, eventNY3))
+	require.NoError(t, eventsRepo.Delete(ctx, eventNextWeek))
 }
 
 func testDayViewQuery(t *testing.T, eventsRepo models.EventsRepo) {
+	ctx := context.Background()
 	ny2021 := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
 	eventNY1, _ := models.NewEvent("", "title1", ny2021, ny2021.Add(time.Hour), "1")
 	eventNY2, _ := models.NewEvent("", "title2", ny2021.Add(time.Hour), ny2021.Add(2*time.Hour), "1")
 	eventNY3, _ := models.NewEvent("", "title3", ny2021.Add(2*time.Hour), ny2021.Add(3*time.Hour), "1")
 	nextDay := ny2021.AddDate(0, 0, 1)
 	eventNextDay, _ := models.NewEvent("", "title4", nextDay, nextDay.Add(time.Hour), "1")
-	require.NoError(t, eventsRepo.Put(eventNY3))
-	require.NoError(t, eventsRepo.Put(eventNY2))
-	require.NoError(t, eventsRepo.Put(eventNY1))
-	require.NoError(t, eventsRepo.Put(eventNextDay))
+	require.NoError(t, eventsRepo.Create(ctx, eventNY3))
+	require.NoError(t, eventsRepo.Create(ctx, eventNY2))
+	require.NoError(t, eventsRepo.Create(ctx, eventNY1))
+	require.NoError(t, eventsRepo.Create(ctx, eventNextDay))
 
-	list, err := eventsRepo.GetDayList(time.Now())
+	list, err := eventsRepo.GetDayList(ctx, time.Now())
 	require.NoError(t, err)
 	require.Len(t, list, 0)
 
-	list, err = eventsRepo.GetDayList(ny2021)
+	list, err = eventsRepo.GetDayList(ctx, ny2021)
 	require.NoError(t, err)
 	require.Len(t, list, 3)
 	require.Equal(t, *eventNY1, *list[0])
 	require.Equal(t, *eventNY2, *list[1])
 	require.Equal(t, *eventNY3, *list[2])
 
-	require.NoError(t, eventsRepo.Delete(eventNY1))
-	list, err = eventsRepo.GetDayList(ny2021)
+	require.NoError(t, eventsRepo.Delete(ctx, eventNY1))
+	list, err = eventsRepo.GetDayList(ctx, ny2021)
 	require.NoError(t, err)
 	require.Len(t, list, 2)
-	require.NoError(t, eventsRepo.Delete(eventNY2))
-	require.NoError(t, eventsRepo.Delete(eventNY3))
-	require.NoError(t, eventsRepo.Delete(eventNextDay))
+	require.NoError(t, eventsRepo.Delete(ctx, eventNY2))
+	require.NoError(t, eventsRepo.Delete(ctx, eventNY3))
+	require.NoError(t, eventsRepo.Delete(ctx, eventNextDay))
 }
 
 func testBasicOperations(t *testing.T, eventsRepo models.EventsRepo) {
+	ctx := context.Background()
 	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
 	event, err := models.NewEvent("", "title", start, start.Add(time.Hour), "1")
 	require.NoError(t, err)
-	require.NoError(t, eventsRepo.Put(event))
+	require.NoError(t, eventsRepo.Create(ctx, event))
 
-	queried, err := eventsRepo.Get(event.ID)
+	queried, err := eventsRepo.Get(ctx, event.ID)
 	require.NoError(t, err)
 	require.Equal(t, *event, *queried)
 
-	list, err := eventsRepo.GetDayList(start)
+	list, err := eventsRepo.GetDayList(ctx, start)
 	require.NoError(t, err)
 	require.Len(t, list, 1)
 	require.Equal(t, *event, *list[0])
 
-	list, err = eventsRepo.GetWeekList(start)
+	list, err = eventsRepo.GetWeekList(ctx, start, time.Sunday)
 	require.NoError(t, err)
 	require.Len(t, list, 1)
 	require.Equal(t, *event, *list[0])
 
-	list, err = eventsRepo.GetMonthList(start)
+	list, err = eventsRepo.GetMonthList(ctx, start)
 	require.NoError(t, err)
 	require.Len(t, list, 1)
 	require.Equal(t, *event, *list[0])
 
-	require.NoError(t, eventsRepo.Delete(event))
-	list, err = eventsRepo.GetDayList(start)
+	require.NoError(t, eventsRepo.Delete(ctx, event))
+	list, err = eventsRepo.GetDayList(ctx, start)
 	require.NoError(t, err)
 	require.Len(t, list, 0)
 }